@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"github.com/byawitz/ggh/internal/config"
+	"os"
+)
+
+func addHost(args []string) error {
+	alias, rest := args[0], args[1:]
+	if len(rest)%2 != 0 {
+		return errors.New("usage: ggh config add <alias> [<keyword> <value>]...")
+	}
+
+	if c, err := config.GetConfig(alias); err != nil {
+		return err
+	} else if c.Name != "" {
+		return fmt.Errorf("host %s is already defined in %s", alias, c.Source())
+	}
+
+	var opts []config.HostOption
+	for i := 0; i < len(rest); i += 2 {
+		opts = append(opts, config.HostOption{Keyword: rest[i], Args: []string{rest[i+1]}})
+	}
+
+	d, err := config.LoadDocument(config.GetConfigPath())
+	if err != nil {
+		return err
+	}
+
+	if err := d.AddHost(alias, opts); err != nil {
+		return err
+	}
+
+	return d.Save()
+}
+
+func setHostOption(args []string) error {
+	d, err := hostDocument(args[0])
+	if err != nil {
+		return err
+	}
+
+	if err := d.Set(args[0], args[1], args[2:]...); err != nil {
+		return err
+	}
+
+	return d.Save()
+}
+
+func removeHost(args []string) error {
+	d, err := hostDocument(args[0])
+	if err != nil {
+		return err
+	}
+
+	if err := d.Remove(args[0]); err != nil {
+		return err
+	}
+
+	return d.Save()
+}
+
+// hostDocument loads the file that defines alias, which may be an included
+// file rather than ~/.ssh/config.
+func hostDocument(alias string) (*config.Document, error) {
+	c, err := config.GetConfig(alias)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.SourceFile == "" {
+		return nil, fmt.Errorf("%s: %w", alias, config.ErrHostNotFound)
+	}
+
+	return config.LoadDocument(c.SourceFile)
+}
+
+func exitOnError(err error) {
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+}
@@ -4,10 +4,13 @@ import (
 	"fmt"
 	"github.com/byawitz/ggh/internal/command"
 	"github.com/byawitz/ggh/internal/config"
+	"github.com/byawitz/ggh/internal/editor"
 	"github.com/byawitz/ggh/internal/history"
 	"github.com/byawitz/ggh/internal/interactive"
 	"github.com/byawitz/ggh/internal/ssh"
+	"log"
 	"os"
+	"time"
 )
 
 func Main() {
@@ -17,23 +20,81 @@ func Main() {
 
 	fmt.Println("\033[2mIn memory of Binyamin Yawitz (1990–2025), creator of GGH \033[31m❤️\033[0m\033[2m\033[0m")
 
-	action, value := command.Which()
+	file, args, err := command.ConfigFile(args)
+	exitOnError(err)
+	if file != "" {
+		config.SetConfigPath(file)
+	}
+
+	action, values := command.Which(args)
 	switch action {
 	case command.InteractiveHistory:
 		args = interactive.History()
 	case command.InteractiveConfig:
 		args = interactive.Config("")
 	case command.InteractiveConfigWithSearch:
-		args = interactive.Config(value)
+		args = interactive.Config(values[0])
 	case command.ListHistory:
-		history.Print()
+		history.Print("")
+		return
+	case command.ListHistoryWithSearch:
+		history.Print(values[0])
 		return
 	case command.ListConfig:
-		config.Print()
+		config.Print("")
+		return
+	case command.ListConfigWithSearch:
+		config.Print(values[0])
+		return
+	case command.Edit:
+		editHost(values[0])
 		return
+	case command.ConfigAdd:
+		exitOnError(addHost(values))
+		return
+	case command.ConfigSet:
+		exitOnError(setHostOption(values))
+		return
+	case command.ConfigRemove:
+		exitOnError(removeHost(values))
+		return
+	case command.Usage:
+		fmt.Println(values[0])
+		os.Exit(1)
 	default:
 
 	}
-	history.AddHistoryFromArgs(args)
-	ssh.Run(args)
+	// ssh reads the last -F wherever it is, and so should the lookups of
+	// the history.
+	if a, err := command.ParseSSH(args); err == nil {
+		if files := a.Values("F"); len(files) > 0 {
+			config.SetConfigPath(files[len(files)-1])
+		}
+	}
+
+	c := history.AddHistoryFromArgs(args)
+
+	start := time.Now()
+	code := ssh.Run(args)
+	history.AddSession(c, history.Session{Date: start, Duration: time.Since(start), ExitCode: code})
+
+	if code != 0 {
+		os.Exit(code)
+	}
+}
+
+func editHost(name string) {
+	c, err := config.GetConfig(name)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if c.SourceFile == "" {
+		fmt.Printf("No host named %s found.\n", name)
+		os.Exit(1)
+	}
+
+	if err := editor.Open(c.SourceFile, c.SourceLine); err != nil {
+		log.Fatal(err)
+	}
 }
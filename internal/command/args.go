@@ -0,0 +1,308 @@
+package command
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// The flags of ssh(1) that take a value, and the ones that do not.
+const (
+	valueFlags = "BbcDEeFIiJLlmOoPpQRSWw"
+	plainFlags = "46AaCfGgKkMNnqsTtVvXxYy"
+)
+
+// Flag is a single ssh option such as -p 2222 or -A, without the dash.
+type Flag struct {
+	Name  string
+	Value string
+}
+
+// SSHArgs is an ssh command line taken apart. User and Port are the ones ssh
+// will use from the command line: like ssh, the first of -l, -p, -o User=,
+// -o Port= and the destination that sets one wins.
+type SSHArgs struct {
+	Flags []Flag
+	// Target is the destination as given, such as root@web or ssh://web:22.
+	Target string
+	// Destination is the host to connect to, an alias or an address, without
+	// user, port or the brackets of an IPv6 literal.
+	Destination string
+	User        string
+	Port        string
+	Command     []string
+}
+
+// ParseSSH parses args the way ssh does. Options may come before and after
+// the destination, which is either [user@]host or ssh://[user@]host[:port];
+// the first argument after it that is not an option starts the remote
+// command.
+func ParseSSH(args []string) (SSHArgs, error) {
+	var a SSHArgs
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+
+		switch {
+		case arg == "--" && a.Destination != "":
+			a.Command = append(a.Command, args[i+1:]...)
+			return a, nil
+		case arg == "--":
+			// Before the destination "--" only ends the options in front of
+			// it; ssh parses options after the destination again.
+			if i+1 < len(args) {
+				i++
+				if err := a.setDestination(args[i]); err != nil {
+					return SSHArgs{}, err
+				}
+			}
+		case len(arg) > 1 && arg[0] == '-':
+			next, err := a.parseFlags(args, i)
+			if err != nil {
+				return SSHArgs{}, err
+			}
+			i = next
+		case a.Destination == "":
+			if err := a.setDestination(arg); err != nil {
+				return SSHArgs{}, err
+			}
+		default:
+			a.Command = append(a.Command, args[i:]...)
+			return a, nil
+		}
+	}
+
+	return a, nil
+}
+
+// parseFlags parses the flags bundled in args[i], such as -Ap2222, and
+// returns the index of the last argument it used.
+func (a *SSHArgs) parseFlags(args []string, i int) (int, error) {
+	arg := args[i]
+
+	for j := 1; j < len(arg); j++ {
+		name := arg[j : j+1]
+
+		switch {
+		case strings.Contains(plainFlags, name):
+			a.Flags = append(a.Flags, Flag{Name: name})
+			continue
+		case !strings.Contains(valueFlags, name):
+			return i, fmt.Errorf("unknown option -- %s", name)
+		}
+
+		value := arg[j+1:]
+		if value == "" {
+			if i+1 >= len(args) {
+				return i, fmt.Errorf("option requires an argument -- %s", name)
+			}
+			i++
+			value = args[i]
+		}
+
+		if err := a.addFlag(name, value); err != nil {
+			return i, err
+		}
+
+		return i, nil
+	}
+
+	return i, nil
+}
+
+func (a *SSHArgs) addFlag(name string, value string) error {
+	a.Flags = append(a.Flags, Flag{Name: name, Value: value})
+
+	switch name {
+	case "l":
+		a.setUser(value)
+	case "p":
+		return a.setPort(value)
+	case "o":
+		keyword, optionValue := splitOption(value)
+		switch strings.ToLower(keyword) {
+		case "user":
+			a.setUser(optionValue)
+		case "port":
+			return a.setPort(optionValue)
+		}
+	}
+
+	return nil
+}
+
+func (a *SSHArgs) setDestination(destination string) error {
+	a.Target = destination
+
+	if rest, ok := strings.CutPrefix(destination, "ssh://"); ok {
+		return a.setURI(rest)
+	}
+
+	if at := strings.LastIndex(destination, "@"); at != -1 {
+		a.setUser(destination[:at])
+		destination = destination[at+1:]
+	}
+
+	a.Destination = unbracket(destination)
+	if a.Destination == "" {
+		return fmt.Errorf("invalid destination %q", destination)
+	}
+
+	return nil
+}
+
+// setURI sets the destination from an ssh URI, given without its scheme.
+func (a *SSHArgs) setURI(uri string) error {
+	invalid := fmt.Errorf("invalid URI %q", "ssh://"+uri)
+
+	uri = strings.TrimSuffix(uri, "/")
+	if strings.Contains(uri, "/") {
+		return invalid
+	}
+
+	if at := strings.LastIndex(uri, "@"); at != -1 {
+		// The user of a URI may carry connection parameters after a ";",
+		// which ssh ignores too.
+		user, _, _ := strings.Cut(uri[:at], ";")
+		user, err := url.PathUnescape(user)
+		if err != nil {
+			return invalid
+		}
+		a.setUser(user)
+		uri = uri[at+1:]
+	}
+
+	host, port := uri, ""
+	if strings.HasPrefix(uri, "[") {
+		end := strings.Index(uri, "]")
+		if end == -1 {
+			return invalid
+		}
+		host, port = uri[1:end], strings.TrimPrefix(uri[end+1:], ":")
+	} else if colon := strings.LastIndex(uri, ":"); colon != -1 {
+		host, port = uri[:colon], uri[colon+1:]
+	}
+
+	if host == "" {
+		return invalid
+	}
+
+	a.Destination = host
+	if port != "" {
+		return a.setPort(port)
+	}
+
+	return nil
+}
+
+func (a *SSHArgs) setUser(user string) {
+	if a.User == "" {
+		a.User = user
+	}
+}
+
+func (a *SSHArgs) setPort(port string) error {
+	if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+		return fmt.Errorf("bad port %q", port)
+	}
+
+	if a.Port == "" {
+		a.Port = port
+	}
+
+	return nil
+}
+
+// Argv returns the arguments in a normal form that ssh parses back to a: one
+// argument per flag and per value, the destination and, after a "--", the
+// remote command.
+func (a SSHArgs) Argv() []string {
+	argv := make([]string, 0, 2*len(a.Flags)+len(a.Command)+2)
+
+	for _, option := range a.Options() {
+		argv = append(argv, option...)
+	}
+
+	if a.Target != "" {
+		argv = append(argv, a.Target)
+	}
+
+	if len(a.Command) > 0 {
+		argv = append(argv, "--")
+		argv = append(argv, a.Command...)
+	}
+
+	return argv
+}
+
+// Options returns every flag as its own argument, followed by its value if it
+// takes one, such as {"-A"} or {"-p", "2222"}.
+func (a SSHArgs) Options() [][]string {
+	options := make([][]string, len(a.Flags))
+	for i, f := range a.Flags {
+		options[i] = []string{"-" + f.Name}
+		if strings.Contains(valueFlags, f.Name) {
+			options[i] = append(options[i], f.Value)
+		}
+	}
+
+	return options
+}
+
+// Values returns the values given to the flag name, in order.
+func (a SSHArgs) Values(name string) []string {
+	var values []string
+	for _, f := range a.Flags {
+		if f.Name == name {
+			values = append(values, f.Value)
+		}
+	}
+
+	return values
+}
+
+func (a SSHArgs) Has(name string) bool {
+	for _, f := range a.Flags {
+		if f.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Option returns the first value given to keyword with -o, which is the one
+// ssh uses.
+func (a SSHArgs) Option(keyword string) (string, bool) {
+	for _, value := range a.Values("o") {
+		if k, v := splitOption(value); strings.EqualFold(k, keyword) {
+			return v, true
+		}
+	}
+
+	return "", false
+}
+
+// splitOption splits an -o value such as "Port=2222" or "Port 2222".
+func splitOption(option string) (string, string) {
+	option = strings.TrimSpace(option)
+
+	end := strings.IndexAny(option, "= \t")
+	if end == -1 {
+		return option, ""
+	}
+
+	keyword, value := option[:end], strings.TrimLeft(option[end:], " \t")
+	value = strings.TrimLeft(strings.TrimPrefix(value, "="), " \t")
+
+	return keyword, value
+}
+
+func unbracket(host string) string {
+	if strings.HasPrefix(host, "[") && strings.HasSuffix(host, "]") {
+		return host[1 : len(host)-1]
+	}
+
+	return host
+}
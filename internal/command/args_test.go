@@ -0,0 +1,109 @@
+package command
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseSSH(t *testing.T) {
+	tests := []struct {
+		args        []string
+		destination string
+		user        string
+		port        string
+		command     []string
+	}{
+		{[]string{"host"}, "host", "", "", nil},
+		{[]string{"root@host", "-p2440"}, "host", "root", "2440", nil},
+		{[]string{"-l", "admin", "-p", "22", "host"}, "host", "admin", "22", nil},
+		{[]string{"-o", "Port=2222", "-oUser deploy", "host"}, "host", "deploy", "2222", nil},
+		{[]string{"-l", "first", "second@host"}, "host", "first", "", nil},
+		{[]string{"me@corp.com@host"}, "host", "me@corp.com", "", nil},
+		{[]string{"ssh://me@[::1]:2200"}, "::1", "me", "2200", nil},
+		{[]string{"ssh://deploy;fingerprint=x@example.com/"}, "example.com", "deploy", "", nil},
+		{[]string{"root@[fe80::1]"}, "fe80::1", "root", "", nil},
+		{[]string{"-J", "bastion", "-F", "cfg", "host", "ls", "-la"}, "host", "", "", []string{"ls", "-la"}},
+		{[]string{"-AtCv", "host", "-p", "22", "--", "uptime"}, "host", "", "22", []string{"uptime"}},
+		{[]string{"-i", "~/.ssh/key", "--", "host", "echo", "-n"}, "host", "", "", []string{"echo", "-n"}},
+		{[]string{"-V"}, "", "", "", nil},
+	}
+
+	for _, tt := range tests {
+		a, err := ParseSSH(tt.args)
+		if err != nil {
+			t.Errorf("ParseSSH(%q) failed: %v", tt.args, err)
+			continue
+		}
+
+		if a.Destination != tt.destination || a.User != tt.user || a.Port != tt.port || !slices.Equal(a.Command, tt.command) {
+			t.Errorf("ParseSSH(%q) = %q, %q, %q, %q, want %q, %q, %q, %q", tt.args,
+				a.Destination, a.User, a.Port, a.Command, tt.destination, tt.user, tt.port, tt.command)
+		}
+	}
+}
+
+func TestParseSSHFlags(t *testing.T) {
+	a, err := ParseSSH([]string{"-AL8080:db:5432", "-i", "a", "-ib", "-o", "ProxyJump = bastion", "host"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !a.Has("A") || a.Has("N") {
+		t.Errorf("Has() does not match the flags %v", a.Flags)
+	}
+
+	if got := a.Values("i"); !slices.Equal(got, []string{"a", "b"}) {
+		t.Errorf("Values(\"i\") = %q, want [a b]", got)
+	}
+
+	if got := a.Values("L"); !slices.Equal(got, []string{"8080:db:5432"}) {
+		t.Errorf("Values(\"L\") = %q, want [8080:db:5432]", got)
+	}
+
+	if got, ok := a.Option("proxyjump"); !ok || got != "bastion" {
+		t.Errorf("Option(\"proxyjump\") = %q, %v, want bastion, true", got, ok)
+	}
+}
+
+func TestArgv(t *testing.T) {
+	tests := []struct {
+		args []string
+		argv []string
+	}{
+		{[]string{"web"}, []string{"web"}},
+		{[]string{"-AtCp2222", "root@web"}, []string{"-A", "-t", "-C", "-p", "2222", "root@web"}},
+		{[]string{"-o", "Port 22", "web", "-L8080:db:5432", "ls", "-la"}, []string{"-o", "Port 22", "-L", "8080:db:5432", "web", "--", "ls", "-la"}},
+		{[]string{"--", "ssh://web:22", "--", "-n"}, []string{"ssh://web:22", "--", "-n"}},
+	}
+
+	for _, tt := range tests {
+		a, err := ParseSSH(tt.args)
+		if err != nil {
+			t.Fatalf("ParseSSH(%q) failed: %v", tt.args, err)
+		}
+
+		argv := a.Argv()
+		if !slices.Equal(argv, tt.argv) {
+			t.Errorf("ParseSSH(%q).Argv() = %q, want %q", tt.args, argv, tt.argv)
+		}
+
+		again, err := ParseSSH(argv)
+		if err != nil || !slices.Equal(again.Argv(), argv) {
+			t.Errorf("ParseSSH(%q).Argv() = %q, %v, want %q", argv, again.Argv(), err, argv)
+		}
+	}
+}
+
+func TestParseSSHErrors(t *testing.T) {
+	for _, args := range [][]string{
+		{"host", "-p"},
+		{"-p", "ssh"},
+		{"-Z", "host"},
+		{"ssh://host/path"},
+		{"root@"},
+	} {
+		if _, err := ParseSSH(args); err == nil {
+			t.Errorf("ParseSSH(%q) succeeded", args)
+		}
+	}
+}
@@ -1,7 +1,8 @@
 package command
 
 import (
-	"os"
+	"errors"
+	"strings"
 )
 
 type Action int
@@ -12,30 +13,92 @@ const (
 	InteractiveConfig
 	InteractiveConfigWithSearch
 	ListHistory
+	ListHistoryWithSearch
 	ListConfig
+	ListConfigWithSearch
+	Edit
+	ConfigAdd
+	ConfigSet
+	ConfigRemove
+	Usage
 )
 
-func Which() (Action, string) {
-	if len(os.Args) == 1 {
-		return InteractiveHistory, ""
+// ConfigFile removes a leading "-F file" from args and returns the file, so
+// that ggh's own commands can be combined with an alternate configuration.
+// A -F without a file is an error.
+func ConfigFile(args []string) (string, []string, error) {
+	switch {
+	case len(args) >= 1 && args[0] == "-F":
+		if len(args) < 2 || args[1] == "" {
+			return "", args, errors.New("usage: ggh -F <file> [arguments]")
+		}
+		return args[1], args[2:], nil
+	case len(args) >= 1 && strings.HasPrefix(args[0], "-F"):
+		return args[0][2:], args[1:], nil
 	}
 
-	if len(os.Args) == 2 {
-		switch os.Args[1] {
+	return "", args, nil
+}
+
+func Which(args []string) (Action, []string) {
+	if len(args) == 0 {
+		return InteractiveHistory, nil
+	}
+
+	if len(args) == 1 {
+		switch args[0] {
 		case "--history":
-			return ListHistory, ""
+			return ListHistory, nil
 		case "--config":
-			return ListConfig, ""
+			return ListConfig, nil
+		case "-":
+			return InteractiveConfig, nil
+		}
+	}
+
+	// A search may be quoted or spread over several arguments, as in
+	// "ggh - tag:prod env:eu".
+	if len(args) >= 2 {
+		query := []string{strings.Join(args[1:], " ")}
+		switch args[0] {
 		case "-":
-			return InteractiveConfig, ""
+			return InteractiveConfigWithSearch, query
+		case "--config":
+			return ListConfigWithSearch, query
+		case "--history":
+			return ListHistoryWithSearch, query
+		}
+	}
+
+	// ggh's own commands are never passed on to ssh, as "ssh config rm" would
+	// connect to a host named config. A wrong number of arguments returns
+	// their usage instead.
+	if len(args) >= 1 && args[0] == "edit" {
+		if len(args) != 2 {
+			return Usage, []string{"usage: ggh edit <alias>"}
 		}
+		return Edit, args[1:]
 	}
 
-	if len(os.Args) == 3 {
-		if os.Args[1] == "-" {
-			return InteractiveConfigWithSearch, os.Args[2]
+	if len(args) >= 2 && args[0] == "config" {
+		switch args[1] {
+		case "add":
+			if len(args) < 3 {
+				return Usage, []string{"usage: ggh config add <alias> [<keyword> <value>]..."}
+			}
+			return ConfigAdd, args[2:]
+		case "set":
+			if len(args) < 5 {
+				return Usage, []string{"usage: ggh config set <alias> <keyword> <value>..."}
+			}
+			return ConfigSet, args[2:]
+		case "rm":
+			if len(args) != 3 {
+				return Usage, []string{"usage: ggh config rm <alias>"}
+			}
+			return ConfigRemove, args[2:]
 		}
 	}
 
-	return PassThrough, ""
+	return PassThrough, nil
 }
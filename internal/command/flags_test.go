@@ -0,0 +1,70 @@
+package command
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestWhich(t *testing.T) {
+	tests := []struct {
+		args   []string
+		action Action
+		values []string
+	}{
+		{nil, InteractiveHistory, nil},
+		{[]string{"-", "tag:prod", "env:eu"}, InteractiveConfigWithSearch, []string{"tag:prod env:eu"}},
+		{[]string{"edit", "web"}, Edit, []string{"web"}},
+		{[]string{"config", "add", "web"}, ConfigAdd, []string{"web"}},
+		{[]string{"config", "set", "web", "Port", "22"}, ConfigSet, []string{"web", "Port", "22"}},
+		{[]string{"config", "rm", "web"}, ConfigRemove, []string{"web"}},
+		{[]string{"config", "uptime"}, PassThrough, nil},
+		{[]string{"root@web", "-p", "22"}, PassThrough, nil},
+	}
+
+	for _, tt := range tests {
+		action, values := Which(tt.args)
+		if action != tt.action || !slices.Equal(values, tt.values) {
+			t.Errorf("Which(%q) = %v, %q, want %v, %q", tt.args, action, values, tt.action, tt.values)
+		}
+	}
+}
+
+func TestWhichUsage(t *testing.T) {
+	for _, args := range [][]string{
+		{"edit"},
+		{"edit", "web", "db"},
+		{"config", "add"},
+		{"config", "set", "web", "Port"},
+		{"config", "rm"},
+		{"config", "rm", "web", "db"},
+	} {
+		if action, values := Which(args); action != Usage || len(values) != 1 {
+			t.Errorf("Which(%q) = %v, %q, want the usage", args, action, values)
+		}
+	}
+}
+
+func TestConfigFile(t *testing.T) {
+	tests := []struct {
+		args []string
+		file string
+		rest []string
+	}{
+		{[]string{"-F", "work", "web"}, "work", []string{"web"}},
+		{[]string{"-Fwork", "-"}, "work", []string{"-"}},
+		{[]string{"web", "-F", "work"}, "", []string{"web", "-F", "work"}},
+	}
+
+	for _, tt := range tests {
+		file, rest, err := ConfigFile(tt.args)
+		if err != nil || file != tt.file || !slices.Equal(rest, tt.rest) {
+			t.Errorf("ConfigFile(%q) = %q, %q, %v, want %q, %q", tt.args, file, rest, err, tt.file, tt.rest)
+		}
+	}
+
+	for _, args := range [][]string{{"-F"}, {"-F", ""}} {
+		if _, _, err := ConfigFile(args); err == nil {
+			t.Errorf("ConfigFile(%q) succeeded", args)
+		}
+	}
+}
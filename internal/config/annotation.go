@@ -0,0 +1,85 @@
+package config
+
+import (
+	"maps"
+	"slices"
+	"strings"
+)
+
+const annotationPrefix = "ggh:"
+
+// annotations is the metadata ggh reads from "# ggh: ..." comments inside a
+// Host block. ssh ignores them like any other comment.
+type annotations struct {
+	tags        []string
+	description string
+	labels      map[string]string
+}
+
+// parseAnnotation reads a comment such as
+//
+//	# ggh: tags=prod,db env=eu description="primary postgres"
+//
+// Words without a value are taken as tags. ok is false when line is not a
+// ggh comment or cannot be tokenized.
+func parseAnnotation(line string) (a annotations, ok bool) {
+	line = strings.Trim(line, whitespace)
+	if !strings.HasPrefix(line, "#") {
+		return a, false
+	}
+
+	line = strings.TrimLeft(line[1:], whitespace)
+	if len(line) < len(annotationPrefix) || !strings.EqualFold(line[:len(annotationPrefix)], annotationPrefix) {
+		return a, false
+	}
+
+	words, err := splitArgs(line[len(annotationPrefix):])
+	if err != nil {
+		return a, false
+	}
+
+	for _, word := range words {
+		key, value, found := strings.Cut(word, "=")
+		key = strings.ToLower(key)
+
+		switch {
+		case !found:
+			a.tags = append(a.tags, word)
+		case key == "tags" || key == "tag":
+			for _, tag := range strings.Split(value, ",") {
+				if tag = strings.TrimSpace(tag); tag != "" {
+					a.tags = append(a.tags, tag)
+				}
+			}
+		case key == "description":
+			a.description = value
+		default:
+			if a.labels == nil {
+				a.labels = map[string]string{}
+			}
+			a.labels[key] = value
+		}
+	}
+
+	return a, true
+}
+
+// merge adds the annotations of another comment of the same block.
+func (a *annotations) merge(other annotations) {
+	for _, tag := range other.tags {
+		if !slices.Contains(a.tags, tag) {
+			a.tags = append(a.tags, tag)
+		}
+	}
+
+	if other.description != "" {
+		a.description = other.description
+	}
+
+	if len(other.labels) > 0 {
+		if a.labels == nil {
+			a.labels = map[string]string{}
+		}
+		maps.Copy(a.labels, other.labels)
+	}
+}
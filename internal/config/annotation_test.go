@@ -0,0 +1,72 @@
+package config
+
+import (
+	"maps"
+	"slices"
+	"testing"
+)
+
+var annotatedConfig = `
+# ggh: tags=ignored, this is outside of any Host block
+Host db1 db1-replica
+	# ggh: tags=prod,db env=eu description="primary postgres"
+	HostName 10.0.0.5
+	#ggh: backup owner=dba
+
+Host web1
+	# a plain comment
+	HostName 10.0.0.7
+
+Match user root
+	# ggh: tags=root
+`
+
+func TestAnnotations(t *testing.T) {
+	configs, err := Parse(annotatedConfig)
+	if err != nil {
+		t.Fatalf("Parsing failed: %v", err)
+	}
+
+	if len(configs) != 3 {
+		t.Fatalf("Parsing failed: got %+v", configs)
+	}
+
+	for _, c := range configs[:2] {
+		if want := []string{"prod", "db", "backup"}; !slices.Equal(c.Tags, want) {
+			t.Errorf("%s: got tags %q, want %q", c.Name, c.Tags, want)
+		}
+
+		if c.Description != "primary postgres" {
+			t.Errorf("%s: got description %q", c.Name, c.Description)
+		}
+
+		if want := map[string]string{"env": "eu", "owner": "dba"}; !maps.Equal(c.Labels, want) {
+			t.Errorf("%s: got labels %v, want %v", c.Name, c.Labels, want)
+		}
+	}
+
+	web := configs[2]
+	if len(web.Tags) != 0 || web.Description != "" || len(web.Labels) != 0 {
+		t.Errorf("web1 should have no annotations, got %+v", web)
+	}
+
+	if got := configs[0].Annotations(); got != "prod,db,backup env=eu owner=dba" {
+		t.Errorf("Annotations() = %q", got)
+	}
+}
+
+func TestMatches(t *testing.T) {
+	c := SSHConfig{Name: "db1", Host: "10.0.0.5", Tags: []string{"prod"}, Description: "Primary Postgres", Labels: map[string]string{"env": "eu"}}
+
+	for _, search := range []string{"", "db", "10.0", "PROD", "postgres", "env=eu", "eu"} {
+		if !c.Matches(search) {
+			t.Errorf("%q should match", search)
+		}
+	}
+
+	for _, search := range []string{"web", "staging", "env=us"} {
+		if c.Matches(search) {
+			t.Errorf("%q should not match", search)
+		}
+	}
+}
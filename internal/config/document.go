@@ -0,0 +1,395 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+var ErrHostNotFound = errors.New("host not found")
+
+// Document is an editable ssh_config file. Lines that are not touched by an
+// edit are written back byte for byte, so comments, ordering, indentation and
+// Include lines survive a round trip.
+type Document struct {
+	Path    string
+	lines   []docLine
+	newline string
+}
+
+// HostOption is a single keyword line of a Host block.
+type HostOption struct {
+	Keyword string
+	Args    []string
+}
+
+// ParseOptions parses options written on a single line and separated by
+// semicolons, such as "ForwardAgent yes; ServerAliveInterval 30".
+func ParseOptions(s string) ([]HostOption, error) {
+	var opts []HostOption
+
+	for _, part := range strings.Split(s, ";") {
+		keyword, args, err := splitLine(part)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", strings.TrimSpace(part), err)
+		}
+
+		if keyword == "" {
+			continue
+		}
+
+		if len(args) == 0 {
+			return nil, fmt.Errorf("%s requires a value", originalKeyword(part))
+		}
+
+		opts = append(opts, HostOption{Keyword: originalKeyword(part), Args: args})
+	}
+
+	return opts, nil
+}
+
+type docLine struct {
+	raw     string
+	keyword string
+	args    []string
+}
+
+func LoadDocument(path string) (*Document, error) {
+	content, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	return ParseDocument(path, string(content))
+}
+
+func ParseDocument(path string, content string) (*Document, error) {
+	d := &Document{Path: path, newline: "\n"}
+	if strings.Contains(content, "\r\n") {
+		d.newline = "\r\n"
+	}
+
+	content = strings.TrimSuffix(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+	if content == "" {
+		return d, nil
+	}
+
+	for i, raw := range strings.Split(content, "\n") {
+		keyword, args, err := splitLine(raw)
+		if err != nil {
+			return nil, &ParseError{File: path, Line: i + 1, Err: err}
+		}
+
+		d.lines = append(d.lines, docLine{raw: raw, keyword: keyword, args: args})
+	}
+
+	return d, nil
+}
+
+func (d *Document) String() string {
+	var b strings.Builder
+
+	for _, l := range d.lines {
+		b.WriteString(l.raw)
+		b.WriteString(d.newline)
+	}
+
+	return b.String()
+}
+
+// HasHost reports whether alias is one of the patterns of a Host line.
+func (d *Document) HasHost(alias string) bool {
+	_, _, ok := d.hostBlock(alias)
+	return ok
+}
+
+// AddHost writes a new Host block for alias. The block is placed before the
+// first wildcard block that would match alias, because ssh uses the first
+// value it finds and a trailing "Host *" would otherwise shadow it.
+func (d *Document) AddHost(alias string, opts []HostOption) error {
+	if !isConcrete(alias) {
+		return fmt.Errorf("%q is not a valid host alias", alias)
+	}
+
+	if d.HasHost(alias) {
+		return fmt.Errorf("host %s already exists in %s", alias, d.Path)
+	}
+
+	block := []docLine{newDocLine("", "Host", alias)}
+	for _, o := range opts {
+		if err := validKeyword(o.Keyword); err != nil {
+			return err
+		}
+
+		block = append(block, newDocLine("\t", o.Keyword, o.Args...))
+	}
+
+	at := len(d.lines)
+	for i, l := range d.lines {
+		if (l.keyword == "host" && !slices.ContainsFunc(l.args, isConcrete) && matchHost(alias, l.args)) ||
+			(l.keyword == "match" && len(l.args) == 1 && strings.EqualFold(l.args[0], "all")) {
+			at = d.attachedCommentStart(i)
+			break
+		}
+	}
+
+	if at > 0 && strings.TrimSpace(d.lines[at-1].raw) != "" {
+		block = append([]docLine{{}}, block...)
+	}
+
+	if at < len(d.lines) {
+		block = append(block, docLine{})
+	}
+
+	d.lines = slices.Insert(d.lines, at, block...)
+
+	return nil
+}
+
+// Set replaces the value of keyword in the Host block defining alias, or adds
+// the keyword at the end of the block when it is not set yet. Every other line
+// setting keyword in the block is removed. When the block
+// lists several aliases the change applies to all of them.
+func (d *Document) Set(alias string, keyword string, values ...string) error {
+	if err := validKeyword(keyword); err != nil {
+		return err
+	}
+
+	start, end, ok := d.hostBlock(alias)
+	if !ok {
+		return fmt.Errorf("%s: %w", alias, ErrHostNotFound)
+	}
+
+	indent := "\t"
+	last := start
+	replaced := false
+	for i := start + 1; i < end; i++ {
+		l := d.lines[i]
+		if l.keyword == "" {
+			continue
+		}
+
+		if l.keyword != strings.ToLower(keyword) {
+			last = i
+			indent = leadingSpace(l.raw)
+			continue
+		}
+
+		// A keyword that may repeat, such as IdentityFile, keeps only the
+		// new value, in place of the first line setting it.
+		if replaced {
+			d.lines = slices.Delete(d.lines, i, i+1)
+			i--
+			end--
+			continue
+		}
+
+		d.lines[i] = newDocLine(leadingSpace(l.raw), originalKeyword(l.raw), values...)
+		replaced = true
+	}
+
+	if !replaced {
+		d.lines = slices.Insert(d.lines, last+1, newDocLine(indent, keyword, values...))
+	}
+
+	return nil
+}
+
+// Remove deletes alias. A Host block that only defines alias is removed
+// entirely; otherwise alias is dropped from its Host line.
+func (d *Document) Remove(alias string) error {
+	start, end, ok := d.hostBlock(alias)
+	if !ok {
+		return fmt.Errorf("%s: %w", alias, ErrHostNotFound)
+	}
+
+	header := d.lines[start]
+	if len(header.args) > 1 {
+		rest := slices.DeleteFunc(slices.Clone(header.args), func(s string) bool { return s == alias })
+		d.lines[start] = newDocLine(leadingSpace(header.raw), originalKeyword(header.raw), rest...)
+		return nil
+	}
+
+	if end < len(d.lines) {
+		end = d.attachedCommentStart(end)
+	}
+
+	d.lines = slices.Delete(d.lines, d.attachedCommentStart(start), end)
+
+	return nil
+}
+
+// Save writes the document atomically, keeping a timestamped backup of the
+// previous content in ~/.ggh/backups.
+func (d *Document) Save() error {
+	mode := os.FileMode(0600)
+
+	// A symlinked config is written through the link, not replaced by a file.
+	path := d.Path
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+
+		previous, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		if err := writeBackup(path, previous, mode); err != nil {
+			return err
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+"-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.WriteString(d.String()); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Chmod(tmp.Name(), mode); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// writeBackup stores content in ~/.ggh/backups, named after the whole path
+// and a timestamp, never overwriting an earlier backup taken within the same
+// second. Backups are kept out of the ssh directory so an Include glob never
+// loads them.
+func writeBackup(path string, content []byte, mode os.FileMode) error {
+	dir := filepath.Join(HomeDir(), ".ggh", "backups")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	name := strings.Trim(strings.NewReplacer("/", "_", `\`, "_", ":", "_").Replace(path), "_")
+	backup := filepath.Join(dir, name+".bak-"+time.Now().Format("20060102-150405"))
+
+	for i := 1; ; i++ {
+		f, err := os.OpenFile(backup, os.O_WRONLY|os.O_CREATE|os.O_EXCL, mode)
+		if errors.Is(err, os.ErrExist) {
+			backup = filepath.Join(dir, fmt.Sprintf("%s.bak-%s-%d", name, time.Now().Format("20060102-150405"), i))
+			continue
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if _, err := f.Write(content); err != nil {
+			f.Close()
+			return err
+		}
+
+		return f.Close()
+	}
+}
+
+// hostBlock returns the line range of the first Host block listing alias. The
+// range ends at the next Host or Match line.
+func (d *Document) hostBlock(alias string) (int, int, bool) {
+	for start, l := range d.lines {
+		if l.keyword != "host" || !slices.Contains(l.args, alias) {
+			continue
+		}
+
+		end := start + 1
+		for end < len(d.lines) && d.lines[end].keyword != "host" && d.lines[end].keyword != "match" {
+			end++
+		}
+
+		return start, end, true
+	}
+
+	return 0, 0, false
+}
+
+// attachedCommentStart walks back from a Host or Match line over the comment
+// lines directly above it, which describe that block.
+func (d *Document) attachedCommentStart(header int) int {
+	start := header
+	for start > 0 && strings.HasPrefix(strings.TrimSpace(d.lines[start-1].raw), "#") {
+		start--
+	}
+
+	return start
+}
+
+func newDocLine(indent string, keyword string, args ...string) docLine {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = quoteArg(arg)
+	}
+
+	raw := indent + keyword
+	if len(quoted) > 0 {
+		raw += " " + strings.Join(quoted, " ")
+	}
+
+	return docLine{raw: raw, keyword: strings.ToLower(keyword), args: args}
+}
+
+func quoteArg(arg string) string {
+	if arg != "" && !strings.ContainsAny(arg, whitespace+`"'#`) {
+		return arg
+	}
+
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(arg) + `"`
+}
+
+func validKeyword(keyword string) error {
+	if keyword == "" || strings.ContainsAny(keyword, whitespace+`"'#=`) {
+		return fmt.Errorf("%q is not a valid keyword", keyword)
+	}
+
+	switch strings.ToLower(keyword) {
+	case "host", "match", "include":
+		return fmt.Errorf("%s cannot be set as a host option", keyword)
+	}
+
+	return nil
+}
+
+func leadingSpace(raw string) string {
+	return raw[:len(raw)-len(strings.TrimLeft(raw, whitespace))]
+}
+
+func originalKeyword(raw string) string {
+	raw = strings.TrimLeft(raw, whitespace)
+	if end := strings.IndexAny(raw, whitespace+"="); end != -1 {
+		return raw[:end]
+	}
+
+	return raw
+}
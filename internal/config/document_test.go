@@ -0,0 +1,237 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var editableConfig = `# managed by hand
+Include conf.d/*
+
+Host web1 web1.prod
+    HostName 10.0.0.1   # primary
+    User deploy
+
+# the database
+Host db1
+	HostName 10.0.0.2
+
+Host *
+	User root
+`
+
+func TestDocumentRoundTrip(t *testing.T) {
+	d, err := ParseDocument("config", editableConfig)
+	if err != nil {
+		t.Fatalf("Parsing failed: %v", err)
+	}
+
+	if d.String() != editableConfig {
+		t.Errorf("round trip changed the document:\n%v", d.String())
+	}
+}
+
+func TestDocumentEdit(t *testing.T) {
+	d, err := ParseDocument("config", editableConfig)
+	if err != nil {
+		t.Fatalf("Parsing failed: %v", err)
+	}
+
+	if err := d.Set("web1", "user", "admin"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := d.Set("web1", "IdentityFile", "~/.ssh/My Keys/id"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := d.Remove("db1"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := d.Remove("web1.prod"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := d.AddHost("cache1", []HostOption{{Keyword: "HostName", Args: []string{"10.0.0.3"}}}); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `# managed by hand
+Include conf.d/*
+
+Host web1
+    HostName 10.0.0.1   # primary
+    User admin
+    IdentityFile "~/.ssh/My Keys/id"
+
+Host cache1
+	HostName 10.0.0.3
+
+Host *
+	User root
+`
+	if d.String() != want {
+		t.Errorf("editing failed: got\n%v\nwant\n%v", d.String(), want)
+	}
+
+	if err := d.AddHost("web1", nil); err == nil {
+		t.Errorf("adding an existing host should fail")
+	}
+
+	if err := d.Set("missing", "User", "root"); err == nil {
+		t.Errorf("setting an option of a missing host should fail")
+	}
+
+	configs, err := Parse(d.String())
+	if err != nil {
+		t.Fatalf("Parsing failed: %v", err)
+	}
+
+	if configs[0].Key != filepath.Join(HomeDir(), ".ssh/My Keys/id") || configs[1].User != "root" {
+		t.Errorf("edited document parsed wrongly: %+v", configs)
+	}
+}
+
+func TestDocumentSetRepeatedKeyword(t *testing.T) {
+	d, err := ParseDocument("config", "Host web\n\tIdentityFile ~/.ssh/old\n\tUser deploy\n\tIdentityFile ~/.ssh/older\n\nHost db\n\tIdentityFile ~/.ssh/db\n")
+	if err != nil {
+		t.Fatalf("Parsing failed: %v", err)
+	}
+
+	if err := d.Set("web", "IdentityFile", "/k"); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "Host web\n\tIdentityFile /k\n\tUser deploy\n\nHost db\n\tIdentityFile ~/.ssh/db\n"
+	if d.String() != want {
+		t.Errorf("Set() gave:\n%s\nwant:\n%s", d.String(), want)
+	}
+}
+
+func TestDocumentSave(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config")
+
+	if err := os.WriteFile(path, []byte(editableConfig), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	d, err := LoadDocument(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := d.Remove("db1"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := d.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	backups, _ := filepath.Glob(filepath.Join(HomeDir(), ".ggh", "backups", "*_config.bak-*"))
+	if len(backups) != 1 {
+		t.Fatalf("expected one backup, got %v", backups)
+	}
+
+	previous, _ := os.ReadFile(backups[0])
+	if string(previous) != editableConfig {
+		t.Errorf("backup does not hold the previous content")
+	}
+
+	saved, _ := os.ReadFile(path)
+	if string(saved) != d.String() {
+		t.Errorf("saved content differs from the document")
+	}
+
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Errorf("expected only the config, got %v", entries)
+	}
+}
+
+func TestDocumentSaveIncludedFile(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	writeSshFile(t, "config", "Include config.d/*\n")
+	team := writeSshFile(t, "config.d/team", "Host web1\n\tHostName web1.com\n\nHost db1\n\tHostName db1.com\n")
+
+	d, err := LoadDocument(team)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := d.Remove("db1"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := d.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	configs, err := Parse("Include config.d/*\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(configs) != 1 || configs[0].Name != "web1" {
+		t.Errorf("expected only web1 after removing db1, got %+v", configs)
+	}
+}
+
+func TestDocumentSaveSymlink(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	dir := t.TempDir()
+	target := filepath.Join(dir, "dotfiles-config")
+	link := filepath.Join(dir, "config")
+
+	if err := os.WriteFile(target, []byte(editableConfig), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.Symlink(target, link); err != nil {
+		t.Skip("symlinks are not supported:", err)
+	}
+
+	d, err := LoadDocument(link)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := d.Remove("db1"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := d.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	if info, err := os.Lstat(link); err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Fatalf("the config is no longer a symlink")
+	}
+
+	saved, _ := os.ReadFile(target)
+	if string(saved) != d.String() {
+		t.Errorf("the link target does not hold the saved content")
+	}
+}
+
+func TestParseOptions(t *testing.T) {
+	opts, err := ParseOptions(`ForwardAgent yes; LocalForward 8080 localhost:80;; SetEnv "GREETING=hello world"`)
+	if err != nil {
+		t.Fatalf("Parsing failed: %v", err)
+	}
+
+	if len(opts) != 3 || opts[0].Keyword != "ForwardAgent" || len(opts[1].Args) != 2 || opts[2].Args[0] != "GREETING=hello world" {
+		t.Errorf("Parsing options failed: got %+v", opts)
+	}
+
+	if _, err := ParseOptions("ForwardAgent"); err == nil {
+		t.Errorf("an option without a value should fail")
+	}
+}
@@ -0,0 +1,207 @@
+package config
+
+import (
+	"cmp"
+	"crypto/sha1"
+	"encoding/hex"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const allTokens = "CdhijkLlnpru"
+
+// tokenKeywords lists the "%" tokens each keyword accepts, as documented in
+// the TOKENS section of ssh_config(5).
+var tokenKeywords = map[string]string{
+	"certificatefile":    allTokens,
+	"controlpath":        allTokens,
+	"identityagent":      allTokens,
+	"identityfile":       allTokens,
+	"knownhostscommand":  allTokens,
+	"localcommand":       allTokens,
+	"localforward":       allTokens,
+	"remotecommand":      allTokens,
+	"remoteforward":      allTokens,
+	"revokedhostkeys":    allTokens,
+	"userknownhostsfile": allTokens,
+	"hostname":           "h",
+	"proxycommand":       "hnpr",
+	"proxyjump":          "hnpr",
+}
+
+// envKeywords accept ${NAME} environment variable references.
+var envKeywords = map[string]bool{
+	"certificatefile":    true,
+	"controlpath":        true,
+	"identityagent":      true,
+	"identityfile":       true,
+	"knownhostscommand":  true,
+	"localforward":       true,
+	"remoteforward":      true,
+	"revokedhostkeys":    true,
+	"userknownhostsfile": true,
+}
+
+// tildeKeywords accept a leading "~" for the home directory.
+var tildeKeywords = map[string]bool{
+	"certificatefile":    true,
+	"controlpath":        true,
+	"identityagent":      true,
+	"identityfile":       true,
+	"knownhostscommand":  true,
+	"revokedhostkeys":    true,
+	"userknownhostsfile": true,
+}
+
+// Expand applies the "~", ${NAME} and "%" expansions ssh performs on the value
+// of keyword for the connection c.
+func Expand(keyword string, value string, c SSHConfig) string {
+	keyword = strings.ToLower(keyword)
+
+	if tildeKeywords[keyword] {
+		value = expandTilde(value)
+	}
+
+	if envKeywords[keyword] {
+		value = expandEnv(value)
+	}
+
+	if tokens, ok := tokenKeywords[keyword]; ok {
+		value = expandTokens(value, tokens, c)
+	}
+
+	return value
+}
+
+func expandAll(keyword string, values []string, c SSHConfig) []string {
+	if values == nil {
+		return nil
+	}
+
+	expanded := make([]string, len(values))
+	for i, value := range values {
+		expanded[i] = Expand(keyword, value, c)
+	}
+
+	return expanded
+}
+
+// expandTilde expands a leading "~" or "~user" to that user's home directory.
+func expandTilde(value string) string {
+	if !strings.HasPrefix(value, "~") {
+		return value
+	}
+
+	name, rest, _ := strings.Cut(value[1:], "/")
+	if name == "" {
+		return filepath.Join(HomeDir(), rest)
+	}
+
+	u, err := user.Lookup(name)
+	if err != nil {
+		return value
+	}
+
+	return filepath.Join(u.HomeDir, rest)
+}
+
+// expandEnv replaces ${NAME} references. Unlike os.ExpandEnv it leaves "$NAME"
+// and unknown variables alone, as ssh only knows the braced form.
+func expandEnv(value string) string {
+	var b strings.Builder
+
+	for {
+		start := strings.Index(value, "${")
+		if start == -1 {
+			break
+		}
+
+		end := strings.IndexByte(value[start:], '}')
+		if end == -1 {
+			break
+		}
+
+		name := value[start+2 : start+end]
+		replacement, ok := os.LookupEnv(name)
+		if !ok {
+			replacement = value[start : start+end+1]
+		}
+
+		b.WriteString(value[:start])
+		b.WriteString(replacement)
+		value = value[start+end+1:]
+	}
+
+	b.WriteString(value)
+
+	return b.String()
+}
+
+func expandTokens(value string, tokens string, c SSHConfig) string {
+	if !strings.Contains(value, "%") {
+		return value
+	}
+
+	var b strings.Builder
+
+	for i := 0; i < len(value); i++ {
+		if value[i] != '%' || i+1 == len(value) {
+			b.WriteByte(value[i])
+			continue
+		}
+
+		i++
+		token := value[i]
+
+		switch {
+		case token == '%':
+			b.WriteByte('%')
+		case strings.IndexByte(tokens, token) != -1:
+			b.WriteString(tokenValue(token, c))
+		default:
+			b.WriteByte('%')
+			b.WriteByte(token)
+		}
+	}
+
+	return b.String()
+}
+
+func tokenValue(token byte, c SSHConfig) string {
+	switch token {
+	case 'C':
+		sum := sha1.Sum([]byte(tokenValue('l', c) + tokenValue('h', c) + tokenValue('p', c) + tokenValue('r', c) + tokenValue('j', c)))
+		return hex.EncodeToString(sum[:])
+	case 'd':
+		return HomeDir()
+	case 'h':
+		return cmp.Or(c.Host, c.Name)
+	case 'i':
+		return strconv.Itoa(os.Getuid())
+	case 'j':
+		return c.ProxyJump
+	case 'k':
+		if aliases := c.Options["hostkeyalias"]; len(aliases) > 0 {
+			return aliases[0]
+		}
+		return cmp.Or(c.Name, c.Host)
+	case 'L':
+		return localName()
+	case 'l':
+		name, _ := os.Hostname()
+		return name
+	case 'n':
+		return cmp.Or(c.Name, c.Host)
+	case 'p':
+		return cmp.Or(c.Port, "22")
+	case 'r':
+		return cmp.Or(c.User, localUser())
+	case 'u':
+		return localUser()
+	}
+
+	return ""
+}
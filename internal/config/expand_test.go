@@ -0,0 +1,86 @@
+package config
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+var tokenConfig = `
+Host web
+	HostName %h.example.com
+	User deploy
+	Port 2222
+	IdentityFile %d/.ssh/id_%h
+	IdentityFile ~/.ssh/%r@%n
+	IdentityFile ${GGH_KEY_DIR}/%%literal
+	ProxyCommand nc -X connect %h %p %u
+	LocalForward ${GGH_SOCKET} /run/%r.sock
+`
+
+func TestExpansion(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("GGH_KEY_DIR", "/keys")
+	t.Setenv("GGH_SOCKET", "/tmp/ggh.sock")
+
+	hosts, err := Parse(tokenConfig)
+	if err != nil {
+		t.Fatalf("Parsing failed: %v", err)
+	}
+
+	c := hosts[0]
+
+	if c.Host != "web.example.com" {
+		t.Errorf("HostName expanded to %q", c.Host)
+	}
+
+	wantKeys := []string{
+		filepath.Join(home, ".ssh/id_web.example.com"),
+		filepath.Join(home, ".ssh/deploy@web"),
+		"/keys/%literal",
+	}
+	if !slices.Equal(c.IdentityFiles, wantKeys) || c.Key != wantKeys[0] {
+		t.Errorf("IdentityFile expanded to %q, want %q", c.IdentityFiles, wantKeys)
+	}
+
+	// %u is not a ProxyCommand token and is passed on untouched.
+	if want := "nc -X connect web.example.com 2222 %u"; c.ProxyCommand != want {
+		t.Errorf("ProxyCommand expanded to %q, want %q", c.ProxyCommand, want)
+	}
+
+	if want := "/tmp/ggh.sock /run/deploy.sock"; c.LocalForward[0] != want {
+		t.Errorf("LocalForward expanded to %q, want %q", c.LocalForward[0], want)
+	}
+
+	if raw := c.Options["identityfile"][0]; raw != "%d/.ssh/id_%h" {
+		t.Errorf("Options should keep the value as written, got %q", raw)
+	}
+}
+
+func TestExpandKeywords(t *testing.T) {
+	t.Setenv("HOME", "/home/ggh")
+	t.Setenv("GGH_TEST", "value")
+
+	c := SSHConfig{Name: "alias", Host: "host", User: "user"}
+
+	tests := []struct {
+		keyword string
+		in      string
+		out     string
+	}{
+		{"IdentityFile", "~", "/home/ggh"},
+		{"identityfile", "~/.ssh/%n-%p", "/home/ggh/.ssh/alias-22"},
+		{"hostname", "%n.%h", "%n.host"},
+		{"proxyjump", "%r@bastion", "user@bastion"},
+		{"controlpath", "${GGH_TEST}/$GGH_TEST/${GGH_UNSET}", "value/$GGH_TEST/${GGH_UNSET}"},
+		{"remotecommand", "echo 100%", "echo 100%"},
+		{"user", "%u", "%u"},
+	}
+
+	for _, tt := range tests {
+		if got := Expand(tt.keyword, tt.in, c); got != tt.out {
+			t.Errorf("Expand(%q, %q) = %q, want %q", tt.keyword, tt.in, got, tt.out)
+		}
+	}
+}
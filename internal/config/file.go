@@ -1,8 +1,11 @@
 package config
 
 import (
+	"cmp"
 	"os"
 	"path/filepath"
+	"runtime"
+	"strings"
 )
 
 func HomeDir() string {
@@ -20,10 +23,79 @@ func GetSshDir() string {
 	return filepath.Join(HomeDir(), ".ssh")
 }
 
-func GetConfigFile() string {
-	sshConfigDir := GetSshDir()
+// ExpandPath expands "~" and "~user" and makes relative paths relative to the
+// ssh directory, as ssh does for Include lines of the user configuration.
+func ExpandPath(path string) string {
+	path = expandTilde(path)
+
+	if filepath.IsAbs(path) {
+		return path
+	}
+
+	return filepath.Join(GetSshDir(), path)
+}
+
+// ShortPath replaces the home directory at the start of path with "~".
+func ShortPath(path string) string {
+	if home := HomeDir(); home != "" && strings.HasPrefix(path, home+string(filepath.Separator)) {
+		return "~" + path[len(home):]
+	}
+
+	return path
+}
+
+// configPath replaces ~/.ssh/config when set with SetConfigPath.
+var configPath string
+
+// SetConfigPath makes ggh read file instead of ~/.ssh/config, as "ssh -F file"
+// does. It takes precedence over the GGH_SSH_CONFIG environment variable.
+func SetConfigPath(file string) {
+	configPath = file
+}
+
+// CustomConfigPath returns the configuration selected with -F or
+// GGH_SSH_CONFIG, or "" when the default files are used.
+func CustomConfigPath() string {
+	return AbsConfigPath(cmp.Or(configPath, os.Getenv("GGH_SSH_CONFIG")))
+}
+
+// AbsConfigPath makes the value of -F absolute, so it names the same file
+// from any directory. "none" is kept, as ssh reads no configuration for it.
+func AbsConfigPath(path string) string {
+	if path == "" || path == "none" {
+		return path
+	}
+
+	if abs, err := filepath.Abs(expandTilde(path)); err == nil {
+		return abs
+	}
+
+	return path
+}
+
+func GetConfigPath() string {
+	return cmp.Or(CustomConfigPath(), filepath.Join(GetSshDir(), "config"))
+}
+
+// systemConfigPath is a variable so tests can point it elsewhere.
+var systemConfigPath = defaultSystemConfigPath()
 
-	config, err := os.ReadFile(filepath.Join(sshConfigDir, "config"))
+func defaultSystemConfigPath() string {
+	if runtime.GOOS == "windows" {
+		return filepath.Join(os.Getenv("PROGRAMDATA"), "ssh", "ssh_config")
+	}
+
+	return "/etc/ssh/ssh_config"
+}
+
+// GetSystemConfigPath returns the system-wide configuration ssh reads after
+// the user's.
+func GetSystemConfigPath() string {
+	return systemConfigPath
+}
+
+func GetConfigFile() string {
+	config, err := os.ReadFile(GetConfigPath())
 	if err != nil {
 		return ""
 	}
@@ -32,7 +104,7 @@ func GetConfigFile() string {
 }
 
 func GetConfig(name string) (SSHConfig, error) {
-	list, err := ParseWithSearch(name, GetConfigFile())
+	list, err := Load()
 	if err != nil {
 		return SSHConfig{}, err
 	}
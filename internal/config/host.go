@@ -0,0 +1,180 @@
+package config
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// Origin tells which configuration file a host was defined in.
+const (
+	OriginUser   = "user"
+	OriginSystem = "system"
+)
+
+type SSHConfig struct {
+	Name           string              `json:"name"`
+	Host           string              `json:"host"`
+	Port           string              `json:"port"`
+	User           string              `json:"user"`
+	Key            string              `json:"key"`
+	IdentityFiles  []string            `json:"identity_files,omitempty"`
+	ProxyJump      string              `json:"proxy_jump,omitempty"`
+	ProxyCommand   string              `json:"proxy_command,omitempty"`
+	LocalForward   []string            `json:"local_forward,omitempty"`
+	RemoteForward  []string            `json:"remote_forward,omitempty"`
+	DynamicForward []string            `json:"dynamic_forward,omitempty"`
+	ForwardAgent   string              `json:"forward_agent,omitempty"`
+	Tags           []string            `json:"-"`
+	Description    string              `json:"-"`
+	Labels         map[string]string   `json:"-"`
+	Options        map[string][]string `json:"-"`
+	SourceFile     string              `json:"-"`
+	SourceLine     int                 `json:"-"`
+	Origin         string              `json:"-"`
+}
+
+func newSSHConfig(alias string, opts options) SSHConfig {
+	sshConfig := SSHConfig{
+		Name:           alias,
+		Host:           alias,
+		Port:           opts.get("port"),
+		User:           opts.get("user"),
+		DynamicForward: opts["dynamicforward"],
+		ForwardAgent:   opts.get("forwardagent"),
+		Options:        opts,
+	}
+
+	if sshConfig.ForwardAgent == "no" {
+		sshConfig.ForwardAgent = ""
+	}
+
+	// Without a HostName ssh connects to the alias itself. Every other token
+	// refers to the expanded HostName, so it is expanded first.
+	sshConfig.Host = Expand("hostname", cmp.Or(opts.get("hostname"), alias), sshConfig)
+
+	sshConfig.ProxyJump = Expand("proxyjump", unlessNone(opts.get("proxyjump")), sshConfig)
+	sshConfig.ProxyCommand = Expand("proxycommand", unlessNone(opts.get("proxycommand")), sshConfig)
+	sshConfig.IdentityFiles = expandAll("identityfile", withoutNone(opts["identityfile"]), sshConfig)
+	sshConfig.LocalForward = expandAll("localforward", opts["localforward"], sshConfig)
+	sshConfig.RemoteForward = expandAll("remoteforward", opts["remoteforward"], sshConfig)
+
+	if len(sshConfig.IdentityFiles) > 0 {
+		sshConfig.Key = sshConfig.IdentityFiles[0]
+	}
+
+	return sshConfig
+}
+
+func unlessNone(value string) string {
+	if value == "none" {
+		return ""
+	}
+
+	return value
+}
+
+// withoutNone drops the "none" values of a keyword that may repeat, such as
+// IdentityFile.
+func withoutNone(values []string) []string {
+	var kept []string
+	for _, value := range values {
+		if value != "none" {
+			kept = append(kept, value)
+		}
+	}
+
+	return kept
+}
+
+// Source returns where the host is defined as "file:line", with the home
+// directory shortened to "~".
+func (c SSHConfig) Source() string {
+	if c.SourceFile == "" {
+		return ""
+	}
+
+	return fmt.Sprintf("%s:%d", ShortPath(c.SourceFile), c.SourceLine)
+}
+
+// Jump describes how the connection reaches the host: through a chain of
+// ProxyJump hosts, looked up in hosts, or a ProxyCommand.
+func (c SSHConfig) Jump(hosts []SSHConfig) string {
+	if c.ProxyJump != "" {
+		var names []string
+		for _, hop := range JumpChain(c, hosts) {
+			names = append(names, hop.Name)
+		}
+
+		return strings.Join(names, chainSeparator)
+	}
+
+	if c.ProxyCommand != "" {
+		return "command"
+	}
+
+	return ""
+}
+
+// Forwards summarizes agent and port forwarding, e.g. "agent L8080 D1080".
+func (c SSHConfig) Forwards() string {
+	var parts []string
+
+	if c.ForwardAgent != "" {
+		parts = append(parts, "agent")
+	}
+
+	for _, f := range c.LocalForward {
+		parts = append(parts, "L"+listenPart(f))
+	}
+
+	for _, f := range c.RemoteForward {
+		parts = append(parts, "R"+listenPart(f))
+	}
+
+	for _, f := range c.DynamicForward {
+		parts = append(parts, "D"+listenPart(f))
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// Annotations summarizes the tags and labels of the host, e.g. "prod,db env=eu".
+func (c SSHConfig) Annotations() string {
+	var parts []string
+
+	if len(c.Tags) > 0 {
+		parts = append(parts, strings.Join(c.Tags, ","))
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(c.Labels)) {
+		parts = append(parts, key+"="+c.Labels[key])
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// Matches reports whether search is found, ignoring case, in the name, host,
+// user, description, tags or labels of the host.
+func (c SSHConfig) Matches(search string) bool {
+	if search == "" {
+		return true
+	}
+
+	search = strings.ToLower(search)
+	fields := append([]string{c.Name, c.Host, c.User, c.Description}, c.Tags...)
+	for key, value := range c.Labels {
+		fields = append(fields, key+"="+value)
+	}
+
+	return slices.ContainsFunc(fields, func(field string) bool {
+		return strings.Contains(strings.ToLower(field), search)
+	})
+}
+
+func listenPart(forward string) string {
+	listen, _, _ := strings.Cut(strings.TrimSpace(forward), " ")
+	return listen
+}
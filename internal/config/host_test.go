@@ -0,0 +1,27 @@
+package config
+
+import (
+	"testing"
+)
+
+func TestNewSSHConfigNone(t *testing.T) {
+	c := newSSHConfig("web", options{
+		"hostname":     {"web.example.com"},
+		"identityfile": {"none"},
+		"proxyjump":    {"none"},
+		"proxycommand": {"none"},
+	})
+
+	if c.Key != "" || c.IdentityFiles != nil {
+		t.Errorf("IdentityFile none gave key %q and identity files %q", c.Key, c.IdentityFiles)
+	}
+
+	if c.ProxyJump != "" || c.ProxyCommand != "" {
+		t.Errorf("none gave jump %q and proxy command %q", c.ProxyJump, c.ProxyCommand)
+	}
+
+	c = newSSHConfig("web", options{"identityfile": {"none", "/keys/web"}})
+	if c.Key != "/keys/web" || len(c.IdentityFiles) != 1 {
+		t.Errorf("IdentityFile none, /keys/web gave key %q and identity files %q", c.Key, c.IdentityFiles)
+	}
+}
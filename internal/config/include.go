@@ -0,0 +1,57 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// maxIncludeDepth matches the nesting limit OpenSSH enforces.
+const maxIncludeDepth = 16
+
+func (p *parser) include(file string, line int, pattern string, scope *block, depth int) error {
+	if depth > maxIncludeDepth {
+		return &ParseError{File: file, Line: line, Err: fmt.Errorf("include nested too deeply (more than %d levels)", maxIncludeDepth)}
+	}
+
+	// Relative paths are relative to the directory of the configuration being
+	// read: ~/.ssh for the user's and /etc/ssh for the system-wide one.
+	full := ExpandPath(pattern)
+	if p.system && !filepath.IsAbs(expandTilde(pattern)) {
+		full = filepath.Join(filepath.Dir(GetSystemConfigPath()), pattern)
+	}
+
+	paths, err := filepath.Glob(full)
+	if err != nil {
+		return &ParseError{File: file, Line: line, Err: fmt.Errorf("invalid include pattern %q: %w", pattern, err)}
+	}
+
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return &ParseError{File: file, Line: line, Err: err}
+		}
+
+		if info.IsDir() {
+			continue
+		}
+
+		if slices.Contains(p.reading, path) {
+			cycle := strings.Join(append(slices.Clone(p.reading), path), " -> ")
+			return &ParseError{File: file, Line: line, Err: fmt.Errorf("include cycle: %s", cycle)}
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return &ParseError{File: file, Line: line, Err: err}
+		}
+
+		if err := p.parse(path, string(content), scope, depth); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
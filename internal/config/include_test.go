@@ -0,0 +1,193 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeSshFile(t *testing.T, name string, content string) string {
+	t.Helper()
+
+	path := filepath.Join(GetSshDir(), name)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestIncludeMultipleGlobs(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	writeSshFile(t, "conf.d/web.conf", "Host web1\n\tHostName web1.com\n")
+	writeSshFile(t, "conf.d/db.conf", "Host db1\n\tHostName db1.com\n")
+	writeSshFile(t, "extra", "Host extra\n\tHostName extra.com\n")
+
+	configs, err := Parse("Include conf.d/*.conf ~/.ssh/extra missing/*.conf\n")
+	if err != nil {
+		t.Fatalf("Parsing failed: %v", err)
+	}
+
+	var names []string
+	for _, c := range configs {
+		names = append(names, c.Name)
+	}
+
+	if got := strings.Join(names, ","); got != "db1,web1,extra" {
+		t.Errorf("Parsing includes failed: got %v, want %v\n", got, "db1,web1,extra")
+	}
+
+	if got := configs[1].Source(); got != "~/.ssh/conf.d/web.conf:1" {
+		t.Errorf("Parsing includes failed: got source %v, want %v\n", got, "~/.ssh/conf.d/web.conf:1")
+	}
+}
+
+func TestIncludeInsideHost(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	writeSshFile(t, "bastion.conf", "User jump\nHost *\n\tPort 2222\n")
+
+	configs, err := Parse("Host bastion\n\tInclude bastion.conf\n\tHostName 10.0.0.1\n\tUser ignored\nHost web1\n")
+	if err != nil {
+		t.Fatalf("Parsing failed: %v", err)
+	}
+
+	want := SSHConfig{Name: "bastion", Host: "10.0.0.1", Port: "2222", User: "jump"}
+	if !sameColumns(configs[0], want) {
+		t.Errorf("Parsing includes failed: got %+v, want %+v\n", configs[0], want)
+	}
+
+	want = SSHConfig{Name: "web1", Host: "web1"}
+	if !sameColumns(configs[1], want) {
+		t.Errorf("Parsing includes failed: got %+v, want %+v\n", configs[1], want)
+	}
+}
+
+func TestIncludeCycle(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	a := writeSshFile(t, "a.conf", "Host a\n\tInclude b.conf\n")
+	writeSshFile(t, "b.conf", "\n\nInclude a.conf\n")
+
+	_, err := Parse("Include a.conf\n")
+
+	var parseError *ParseError
+	if !errors.As(err, &parseError) {
+		t.Fatalf("expected a parse error, got %v", err)
+	}
+
+	if parseError.File != filepath.Join(GetSshDir(), "b.conf") || parseError.Line != 3 {
+		t.Errorf("expected the error to point at b.conf:3, got %v", err)
+	}
+
+	if !strings.Contains(err.Error(), a) {
+		t.Errorf("expected the cycle to name %v, got %v", a, err)
+	}
+}
+
+func TestIncludeBadPattern(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	_, err := Parse("Host a\n\tInclude conf.d/[.conf\n")
+
+	var parseError *ParseError
+	if !errors.As(err, &parseError) || parseError.Line != 2 {
+		t.Fatalf("expected a parse error on line 2, got %v", err)
+	}
+}
+
+func TestLoadSystemConfig(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("GGH_SSH_CONFIG", "")
+
+	etc := t.TempDir()
+	systemConfigPath = filepath.Join(etc, "ssh_config")
+	t.Cleanup(func() { systemConfigPath = defaultSystemConfigPath() })
+
+	writeSshFile(t, "config", "Host web\n\tUser me\n")
+
+	system := "Include ssh_config.d/*.conf\n\nHost web\n\tHostName web.example.com\n\tUser root\n"
+	if err := os.MkdirAll(filepath.Join(etc, "ssh_config.d"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(systemConfigPath, []byte(system), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(etc, "ssh_config.d", "jump.conf"), []byte("Host jump\n\tPort 2222\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	configs, err := Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	want := []SSHConfig{
+		{Name: "web", Host: "web.example.com", User: "me"},
+		{Name: "jump", Host: "jump", Port: "2222"},
+	}
+
+	if len(configs) != len(want) {
+		t.Fatalf("Load failed: got %+v", configs)
+	}
+
+	for i := range want {
+		if !sameColumns(configs[i], want[i]) {
+			t.Errorf("Load failed: got %+v, want %+v", configs[i], want[i])
+		}
+	}
+
+	if configs[0].Origin != OriginUser || configs[1].Origin != OriginSystem {
+		t.Errorf("wrong origins: %q, %q", configs[0].Origin, configs[1].Origin)
+	}
+
+	if hosts := WithoutSystem(configs); len(hosts) != 1 || hosts[0].Name != "web" {
+		t.Errorf("WithoutSystem failed: got %+v", hosts)
+	}
+}
+
+func TestLoadCustomConfig(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	dir := t.TempDir()
+	systemConfigPath = filepath.Join(dir, "ssh_config")
+	t.Cleanup(func() { systemConfigPath = defaultSystemConfigPath() })
+
+	writeSshFile(t, "config", "Host home\n")
+	writeSshFile(t, "shared.conf", "Host shared\n")
+
+	custom := filepath.Join(dir, "acme_config")
+	if err := os.WriteFile(custom, []byte("Include shared.conf\nHost acme-web\n\tUser acme\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(systemConfigPath, []byte("Host system\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("GGH_SSH_CONFIG", custom)
+
+	configs, err := Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	// Like ssh -F, the custom file replaces both the user and the system
+	// configuration, while relative includes still resolve to ~/.ssh.
+	if len(configs) != 2 || configs[0].Name != "shared" || configs[1].Name != "acme-web" {
+		t.Fatalf("Load with GGH_SSH_CONFIG failed: got %+v", configs)
+	}
+
+	SetConfigPath(filepath.Join(GetSshDir(), "config"))
+	t.Cleanup(func() { SetConfigPath("") })
+
+	if c, err := GetConfig("home"); err != nil || c.SourceFile != GetConfigPath() {
+		t.Errorf("SetConfigPath should take precedence over GGH_SSH_CONFIG, got %+v, %v", c, err)
+	}
+}
@@ -0,0 +1,107 @@
+package config
+
+import (
+	"cmp"
+	"net"
+	"os"
+	"strings"
+)
+
+const chainSeparator = " → "
+
+// Hop is one host a connection passes through before reaching its target.
+type Hop struct {
+	Name    string
+	Host    string
+	User    string
+	Port    string
+	Defined bool
+}
+
+func (h Hop) String() string {
+	return h.User + "@" + net.JoinHostPort(h.Host, h.Port)
+}
+
+// JumpChain returns the bastions a connection to c goes through, in the order
+// they are connected to. Like ssh, the ProxyJump of the first bastion is
+// followed as well, so chains configured across several Host blocks are
+// expanded.
+func JumpChain(c SSHConfig, hosts []SSHConfig) []Hop {
+	return jumpChain(c, hosts, map[string]bool{c.Name: true})
+}
+
+func jumpChain(c SSHConfig, hosts []SSHConfig, seen map[string]bool) []Hop {
+	if c.ProxyJump == "" {
+		return nil
+	}
+
+	var hops []Hop
+
+	for i, jump := range strings.Split(c.ProxyJump, ",") {
+		hop, hopConfig := resolveHop(strings.TrimSpace(jump), hosts)
+
+		if i == 0 && hop.Defined && !seen[hop.Name] {
+			seen[hop.Name] = true
+			hops = append(hops, jumpChain(hopConfig, hosts, seen)...)
+		}
+
+		hops = append(hops, hop)
+	}
+
+	return hops
+}
+
+// ChainString renders the full route to c, e.g. "laptop → bastion → db01".
+func ChainString(c SSHConfig, hosts []SSHConfig) string {
+	parts := []string{localName()}
+	for _, hop := range JumpChain(c, hosts) {
+		parts = append(parts, hop.Name)
+	}
+
+	return strings.Join(append(parts, cmp.Or(c.Name, c.Host)), chainSeparator)
+}
+
+// resolveHop parses a ProxyJump entry, "[user@]host[:port]" or
+// "ssh://[user@]host[:port]", and looks the host up in hosts.
+func resolveHop(jump string, hosts []SSHConfig) (Hop, SSHConfig) {
+	jump = strings.TrimPrefix(jump, "ssh://")
+
+	hop := Hop{}
+	if at := strings.LastIndex(jump, "@"); at != -1 {
+		hop.User, jump = jump[:at], jump[at+1:]
+	}
+
+	hop.Name = jump
+	if host, port, err := net.SplitHostPort(jump); err == nil {
+		hop.Name, hop.Port = host, port
+	}
+	hop.Name = strings.Trim(hop.Name, "[]")
+	hop.Host = hop.Name
+
+	var found SSHConfig
+	for _, h := range hosts {
+		if h.Name == hop.Name {
+			found = h
+			hop.Defined = true
+			hop.Host = h.Host
+			hop.User = cmp.Or(hop.User, h.User)
+			hop.Port = cmp.Or(hop.Port, h.Port)
+			break
+		}
+	}
+
+	hop.User = cmp.Or(hop.User, localUser())
+	hop.Port = cmp.Or(hop.Port, "22")
+
+	return hop, found
+}
+
+func localName() string {
+	name, err := os.Hostname()
+	if err != nil || name == "" {
+		return "local"
+	}
+
+	short, _, _ := strings.Cut(name, ".")
+	return short
+}
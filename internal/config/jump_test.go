@@ -0,0 +1,51 @@
+package config
+
+import (
+	"testing"
+)
+
+var jumpConfig = `
+Host db01
+	HostName 10.0.1.5
+	ProxyJump jump-db,ghost
+
+Host jump-db
+	HostName 10.0.0.9
+	User ops
+	ProxyJump deploy@bastion-eu:2222
+
+Host bastion-eu
+	HostName bastion.example.com
+	ProxyJump db01
+`
+
+func TestJumpChain(t *testing.T) {
+	hosts, err := Parse(jumpConfig)
+	if err != nil {
+		t.Fatalf("Parsing failed: %v", err)
+	}
+
+	// bastion-eu jumps back through db01, which is listed but not followed again.
+	hops := JumpChain(hosts[0], hosts)
+
+	want := []Hop{
+		{Name: "db01", Host: "10.0.1.5", User: localUser(), Port: "22", Defined: true},
+		{Name: "bastion-eu", Host: "bastion.example.com", User: "deploy", Port: "2222", Defined: true},
+		{Name: "jump-db", Host: "10.0.0.9", User: "ops", Port: "22", Defined: true},
+		{Name: "ghost", Host: "ghost", User: localUser(), Port: "22", Defined: false},
+	}
+
+	if len(hops) != len(want) {
+		t.Fatalf("JumpChain failed: got %+v, want %+v", hops, want)
+	}
+
+	for i := range want {
+		if hops[i] != want[i] {
+			t.Errorf("JumpChain hop %d: got %+v, want %+v", i, hops[i], want[i])
+		}
+	}
+
+	if got := hosts[0].Jump(hosts); got != "db01 → bastion-eu → jump-db → ghost" {
+		t.Errorf("Jump failed: got %v", got)
+	}
+}
@@ -0,0 +1,86 @@
+package config
+
+import (
+	"errors"
+	"strings"
+)
+
+var errUnterminatedQuote = errors.New("unterminated quote")
+
+const whitespace = " \t\r\n\f\v"
+
+// splitLine tokenizes a single ssh_config line the way ssh_config(5) describes it:
+// the keyword is case-insensitive and may be separated from its arguments by
+// whitespace or an optional "=", arguments may be quoted, and an unquoted "#"
+// at the start of an argument begins a trailing comment.
+func splitLine(line string) (string, []string, error) {
+	line = strings.Trim(line, whitespace)
+	if line == "" || line[0] == '#' {
+		return "", nil, nil
+	}
+
+	end := strings.IndexAny(line, whitespace+"=")
+	if end == -1 {
+		return strings.ToLower(line), nil, nil
+	}
+
+	keyword := strings.ToLower(line[:end])
+	rest := strings.TrimLeft(line[end:], whitespace)
+	if strings.HasPrefix(rest, "=") {
+		rest = strings.TrimLeft(rest[1:], whitespace)
+	}
+
+	args, err := splitArgs(rest)
+	if err != nil {
+		return keyword, nil, err
+	}
+
+	return keyword, args, nil
+}
+
+func splitArgs(s string) ([]string, error) {
+	var args []string
+	var arg strings.Builder
+	var quote byte
+	inArg := false
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+
+		if quote == 0 && strings.IndexByte(whitespace, c) != -1 {
+			if inArg {
+				args = append(args, arg.String())
+				arg.Reset()
+				inArg = false
+			}
+			continue
+		}
+
+		if quote == 0 && !inArg && c == '#' {
+			break
+		}
+
+		inArg = true
+		switch {
+		case c == '\\' && i+1 < len(s) && strings.IndexByte(`\"' `, s[i+1]) != -1:
+			i++
+			arg.WriteByte(s[i])
+		case quote == 0 && (c == '"' || c == '\''):
+			quote = c
+		case quote != 0 && c == quote:
+			quote = 0
+		default:
+			arg.WriteByte(c)
+		}
+	}
+
+	if quote != 0 {
+		return nil, errUnterminatedQuote
+	}
+
+	if inArg {
+		args = append(args, arg.String())
+	}
+
+	return args, nil
+}
@@ -0,0 +1,163 @@
+package config
+
+import (
+	"cmp"
+	"os/user"
+	"path"
+	"strings"
+)
+
+// cumulative keywords collect every value from every matching block instead
+// of keeping only the first one.
+var cumulative = map[string]bool{
+	"identityfile":    true,
+	"certificatefile": true,
+	"localforward":    true,
+	"remoteforward":   true,
+	"dynamicforward":  true,
+	"sendenv":         true,
+}
+
+type options map[string][]string
+
+func (o options) get(keyword string) string {
+	if values := o[keyword]; len(values) > 0 {
+		return values[0]
+	}
+
+	return ""
+}
+
+// resolve applies every block that matches alias in file order. The first
+// value found for a keyword wins, as it does in ssh.
+func resolve(alias string, blocks []*block) options {
+	resolved := options{}
+
+	for _, b := range blocks {
+		if !b.matches(alias, resolved) {
+			continue
+		}
+
+		for _, o := range b.options {
+			if _, ok := resolved[o.keyword]; ok && !cumulative[o.keyword] {
+				continue
+			}
+
+			resolved[o.keyword] = append(resolved[o.keyword], strings.Join(o.args, " "))
+		}
+	}
+
+	return resolved
+}
+
+func (b *block) matches(alias string, resolved options) bool {
+	if b.parent != nil && !b.parent.matches(alias, resolved) {
+		return false
+	}
+
+	switch b.kind {
+	case hostBlock:
+		return matchHost(alias, b.patterns)
+	case matchBlock:
+		return matchCriteria(alias, b.patterns, resolved)
+	}
+
+	return true
+}
+
+// matchHost reports whether alias matches a Host line: at least one pattern
+// must match and no negated pattern may match.
+func matchHost(alias string, patterns []string) bool {
+	matched := false
+
+	for _, pattern := range patterns {
+		negated := strings.HasPrefix(pattern, "!")
+		pattern = strings.TrimPrefix(pattern, "!")
+
+		if !matchPattern(alias, pattern) {
+			continue
+		}
+
+		if negated {
+			return false
+		}
+
+		matched = true
+	}
+
+	return matched
+}
+
+// matchList matches a comma separated pattern list, as used by Match criteria.
+func matchList(s string, list string) bool {
+	return matchHost(s, strings.Split(list, ","))
+}
+
+func matchPattern(s string, pattern string) bool {
+	matched, err := path.Match(strings.ToLower(escapeBrackets(pattern)), strings.ToLower(s))
+
+	return err == nil && matched
+}
+
+// ssh patterns only know "*" and "?", so any other glob syntax understood by
+// path.Match has to be taken literally.
+func escapeBrackets(pattern string) string {
+	return strings.NewReplacer(`\`, `\\`, "[", `\[`, "]", `\]`).Replace(pattern)
+}
+
+// matchCriteria evaluates the criteria of a Match line. Criteria that depend
+// on running commands, canonicalization or the network cannot be known without
+// ssh and are treated as not matching.
+func matchCriteria(alias string, criteria []string, resolved options) bool {
+	for i := 0; i < len(criteria); i++ {
+		criterion := strings.ToLower(criteria[i])
+		negated := strings.HasPrefix(criterion, "!")
+		criterion = strings.TrimPrefix(criterion, "!")
+
+		if criterion == "all" {
+			if negated {
+				return false
+			}
+			continue
+		}
+
+		if criterion == "canonical" || criterion == "final" {
+			return negated
+		}
+
+		if i+1 >= len(criteria) {
+			return false
+		}
+		i++
+		arg := criteria[i]
+
+		var matched bool
+		switch criterion {
+		case "host":
+			matched = matchList(cmp.Or(resolved.get("hostname"), alias), arg)
+		case "originalhost":
+			matched = matchList(alias, arg)
+		case "user":
+			matched = matchList(cmp.Or(resolved.get("user"), localUser()), arg)
+		case "localuser":
+			matched = matchList(localUser(), arg)
+		default:
+			return false
+		}
+
+		if matched == negated {
+			return false
+		}
+	}
+
+	return true
+}
+
+func localUser() string {
+	u, err := user.Current()
+	if err != nil {
+		return ""
+	}
+
+	return u.Username
+}
@@ -1,24 +1,63 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
 	"log"
+	"maps"
 	"os"
-	"regexp"
+	"slices"
 	"strings"
 
-	"path/filepath"
-
+	"github.com/byawitz/ggh/internal/settings"
 	"github.com/byawitz/ggh/internal/theme"
 	"github.com/charmbracelet/bubbles/table"
 )
 
-type SSHConfig struct {
-	Name string `json:"name"`
-	Host string `json:"host"`
-	Port string `json:"port"`
-	User string `json:"user"`
-	Key  string `json:"key"`
+type blockKind int
+
+const (
+	globalBlock blockKind = iota
+	hostBlock
+	matchBlock
+)
+
+type option struct {
+	keyword string
+	args    []string
+	line    int
+}
+
+// block is a run of options that share a condition: the lines before the
+// first Host or Match keyword, a Host section or a Match section. Blocks read
+// from a file included inside a Host or Match section only apply when their
+// parent does.
+type block struct {
+	kind     blockKind
+	patterns []string
+	options  []option
+	file     string
+	line     int
+	parent   *block
+	system   bool
+	// meta holds the "# ggh:" annotations of a Host block. It is shared with
+	// the continuation of the block after an Include.
+	meta *annotations
+}
+
+type ParseError struct {
+	File string
+	Line int
+	Err  error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("%s:%d: %v", e.File, e.Line, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
 }
 
 func Parse(configFile string) ([]SSHConfig, error) {
@@ -26,128 +65,212 @@ func Parse(configFile string) ([]SSHConfig, error) {
 }
 
 func ParseWithSearch(search string, configFile string) ([]SSHConfig, error) {
-	configsStrings := strings.Split(strings.ReplaceAll(configFile, "\r\n", "\n"), "Host ")
-	var configs = make([]SSHConfig, 0)
+	p := &parser{}
+	if err := p.parse(GetConfigPath(), configFile, nil, 0); err != nil {
+		return nil, err
+	}
+
+	return buildConfigs(search, p.blocks), nil
+}
+
+// Load parses the user configuration followed by the system-wide one. As in
+// ssh, the first value obtained for an option wins, so the user configuration
+// takes precedence. A configuration chosen with -F replaces both.
+func Load() ([]SSHConfig, error) {
+	p := &parser{}
+	if err := p.parse(GetConfigPath(), GetConfigFile(), nil, 0); err != nil {
+		return nil, err
+	}
 
-	for _, config := range configsStrings {
-		lines := strings.Split(config, "\n")
+	if CustomConfigPath() != "" {
+		return buildConfigs("", p.blocks), nil
+	}
+
+	content, err := os.ReadFile(GetSystemConfigPath())
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	if err == nil {
+		p.system = true
+		if err := p.parse(GetSystemConfigPath(), string(content), nil, 0); err != nil {
+			return nil, err
+		}
+	}
+
+	return buildConfigs("", p.blocks), nil
+}
 
-		if strings.Trim(lines[0], " ") == "" {
+type parser struct {
+	blocks []*block
+	// system is set while the system-wide configuration is parsed.
+	system bool
+	// reading holds the chain of files currently being parsed, outermost first.
+	reading []string
+}
+
+func (p *parser) parse(file string, content string, parent *block, depth int) error {
+	p.reading = append(p.reading, file)
+	defer func() { p.reading = p.reading[:len(p.reading)-1] }()
+
+	lines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+
+	current := &block{kind: globalBlock, file: file, line: 1, parent: parent, system: p.system}
+	p.blocks = append(p.blocks, current)
+
+	for i, line := range lines {
+		if a, ok := parseAnnotation(line); ok {
+			if current.meta != nil {
+				current.meta.merge(a)
+			}
 			continue
 		}
 
-		sshConfig := SSHConfig{
-			Name: lines[0],
-			Port: "",
-			User: "",
+		keyword, args, err := splitLine(line)
+		if err != nil {
+			return &ParseError{File: file, Line: i + 1, Err: err}
 		}
 
-		for _, line := range lines {
-			if len(line) == 0 || line[0] == '#' {
-				continue
+		switch keyword {
+		case "":
+			continue
+		case "host", "match":
+			if len(args) == 0 {
+				return &ParseError{File: file, Line: i + 1, Err: fmt.Errorf("%s directive requires an argument", keyword)}
+			}
+
+			current = &block{kind: hostBlock, patterns: args, file: file, line: i + 1, parent: parent, system: p.system, meta: &annotations{}}
+			if keyword == "match" {
+				current.kind, current.meta = matchBlock, nil
 			}
 
-			line = strings.ReplaceAll(strings.TrimLeft(line, " \t"), "\t", " ")
-			m1 := regexp.MustCompile(` ( *)`)
-			line = m1.ReplaceAllString(line, " ")
+			p.blocks = append(p.blocks, current)
+		case "include":
+			if len(args) == 0 {
+				return &ParseError{File: file, Line: i + 1, Err: fmt.Errorf("include directive requires an argument")}
+			}
 
-			lineData := strings.Split(line, " ")
-			value := ""
-			if len(lineData) > 1 {
-				value = lineData[1]
+			scope := current
+			if scope.kind == globalBlock {
+				scope = scope.parent
 			}
-			switch {
-			case strings.Contains(line, "Include"):
-				result, err := ParseInclude(search, value)
-				if err != nil {
-					panic(err)
+
+			for _, pattern := range args {
+				if err := p.include(file, i+1, pattern, scope, depth+1); err != nil {
+					return err
 				}
-				configs = append(configs, result...)
-			case strings.Contains(line, "Host"):
-				sshConfig.Host = value
-			case strings.Contains(line, "Port"):
-				sshConfig.Port = value
-			case strings.Contains(line, "User"):
-				sshConfig.User = value
-			case strings.Contains(line, "IdentityFile"):
-				sshConfig.Key = value
 			}
-		}
 
-		if sshConfig.Host == "" || !strings.Contains(sshConfig.Name, search) {
-			continue
+			// Options following the Include still belong to the enclosing
+			// block but must rank after everything the included files set.
+			current = &block{kind: current.kind, patterns: current.patterns, file: file, line: current.line, parent: current.parent, system: p.system, meta: current.meta}
+			p.blocks = append(p.blocks, current)
+		default:
+			current.options = append(current.options, option{keyword: keyword, args: args, line: i + 1})
 		}
-
-		configs = append(configs, sshConfig)
-
 	}
 
-	return configs, nil
+	return nil
 }
 
-func ParseInclude(search string, path string) ([]SSHConfig, error) {
-	var results = make([]SSHConfig, 0)
-
-	var isAbsolute = path[0] == '/' || path[0] == '~'
+func buildConfigs(search string, blocks []*block) []SSHConfig {
+	var configs = make([]SSHConfig, 0)
 
-	var paths []string
-	var err error
+	for _, alias := range aliases(blocks) {
+		if !strings.Contains(alias.name, search) {
+			continue
+		}
 
-	if isAbsolute {
-		if path[0] == '~' {
-			path = filepath.Join(HomeDir(), path[2:])
+		sshConfig := newSSHConfig(alias.name, resolve(alias.name, blocks))
+		sshConfig.SourceFile = alias.definition.file
+		sshConfig.SourceLine = alias.definition.line
+		sshConfig.Origin = OriginUser
+		if alias.definition.system {
+			sshConfig.Origin = OriginSystem
 		}
-	} else {
-		path = filepath.Join(GetSshDir(), path)
-	}
 
-	paths, err = filepath.Glob(path)
+		if meta := alias.definition.meta; meta != nil {
+			sshConfig.Tags = slices.Clone(meta.tags)
+			sshConfig.Description = meta.description
+			sshConfig.Labels = maps.Clone(meta.labels)
+		}
 
-	if err != nil {
-		return nil, err
+		configs = append(configs, sshConfig)
 	}
 
-	for _, path := range paths {
-		info, err := os.Stat(path)
+	return configs
+}
 
-		if err != nil {
-			return nil, err
-		}
+type alias struct {
+	name       string
+	definition *block
+}
+
+// aliases returns the concrete Host patterns in the order they are first
+// defined. Wildcard and negated patterns only describe rules for other hosts
+// and cannot be connected to by name.
+func aliases(blocks []*block) []alias {
+	var names []alias
 
-		if info.IsDir() {
+	for _, b := range blocks {
+		if b.kind != hostBlock {
 			continue
 		}
 
-		fileContent, err := os.ReadFile(path)
-		if err != nil {
-			return nil, err
-		}
+		for _, pattern := range b.patterns {
+			defined := slices.ContainsFunc(names, func(a alias) bool { return a.name == pattern })
+			if !isConcrete(pattern) || defined || !b.matches(pattern, options{}) {
+				continue
+			}
 
-		items, err := ParseWithSearch(search, string(fileContent))
-		if err != nil {
-			return nil, err
+			names = append(names, alias{name: pattern, definition: b})
 		}
-		results = append(results, items...)
 	}
 
-	return results, nil
+	return names
 }
 
-func Print() {
-	list, err := Parse(GetConfigFile())
+// WithoutSystem drops the hosts defined in the system-wide configuration.
+func WithoutSystem(list []SSHConfig) []SSHConfig {
+	return slices.DeleteFunc(slices.Clone(list), func(c SSHConfig) bool {
+		return c.Origin == OriginSystem
+	})
+}
+
+func isConcrete(pattern string) bool {
+	return pattern != "" && !strings.ContainsAny(pattern, "*?!")
+}
+
+// Print lists the hosts matching query, see Query.
+func Print(query string) {
+	list, err := Load()
 
 	if err != nil {
 		log.Fatal(err)
 	}
 
+	hosts := list
+	q := ParseQuery(query)
+	list = slices.DeleteFunc(slices.Clone(list), func(c SSHConfig) bool { return !q.Matches(c) })
+
+	s := settings.FetchWithDefaultFile()
+
+	if s.HideSystemHosts {
+		list = WithoutSystem(list)
+	}
+
 	if len(list) == 0 {
-		fmt.Println("No configs found in ~/.ssh/config.")
+		fmt.Printf("No configs found in %s.\n", ShortPath(GetConfigPath()))
 		return
 	}
 
+	if s.ResolveWithSSH {
+		list = ResolveAll(list)
+	}
+
 	var rows []table.Row
 	for _, history := range list {
-		rows = append(rows, table.Row{history.Name, history.Host, history.Port, history.User, history.Key})
+		rows = append(rows, table.Row{history.Name, history.Host, history.Port, history.User, ShortPath(history.Key), history.Jump(hosts), history.Forwards(), history.Annotations(), history.Source()})
 	}
 	fmt.Println(theme.PrintTable(rows, theme.PrintConfig))
 
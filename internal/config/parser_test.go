@@ -1,6 +1,8 @@
 package config
 
 import (
+	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -46,3 +48,200 @@ func TestParsingWithSearch(t *testing.T) {
 		t.Errorf("Parsing config file failed: got %v, want %v\n", len(configs), 1)
 	}
 }
+
+var generatedConfig = `
+# generated by tooling
+Host=db1
+	HOSTNAME = "db1.internal"
+	port=2222
+	user	"deploy"
+	IdentityFile "~/.ssh/My Keys/id_ed25519" # trailing comment
+
+Match host db1 exec "test -f /tmp/flag"
+	User matched
+
+host web1
+  hostname web1.example.com
+`
+
+func TestParsingGrammar(t *testing.T) {
+	configs, err := Parse(generatedConfig)
+
+	if err != nil {
+		t.Fatalf("Parsing failed: %v", err)
+	}
+
+	if len(configs) != 2 {
+		t.Fatalf("Parsing config file failed: got %v, want %v\n", len(configs), 2)
+	}
+
+	want := SSHConfig{Name: "db1", Host: "db1.internal", Port: "2222", User: "deploy", Key: filepath.Join(HomeDir(), ".ssh/My Keys/id_ed25519")}
+	if !sameColumns(configs[0], want) {
+		t.Errorf("Parsing config file failed: got %+v, want %+v\n", configs[0], want)
+	}
+
+	if configs[1].Name != "web1" || configs[1].Host != "web1.example.com" {
+		t.Errorf("Parsing config file failed: got %+v\n", configs[1])
+	}
+}
+
+func TestSplitLine(t *testing.T) {
+	tests := []struct {
+		line    string
+		keyword string
+		args    []string
+	}{
+		{"Host web1", "host", []string{"web1"}},
+		{"\tHostName\t10.0.0.1", "hostname", []string{"10.0.0.1"}},
+		{"Port=22", "port", []string{"22"}},
+		{"User = root", "user", []string{"root"}},
+		{`ProxyCommand "ssh -W %h:%p bastion"`, "proxycommand", []string{"ssh -W %h:%p bastion"}},
+		{`IdentityFile 'a b' c\ d`, "identityfile", []string{"a b", "c d"}},
+		{"Host a b # comment", "host", []string{"a", "b"}},
+		{"  # comment", "", nil},
+	}
+
+	for _, test := range tests {
+		keyword, args, err := splitLine(test.line)
+		if err != nil {
+			t.Fatalf("splitLine(%q) failed: %v", test.line, err)
+		}
+
+		if keyword != test.keyword || strings.Join(args, "|") != strings.Join(test.args, "|") {
+			t.Errorf("splitLine(%q): got %q %q, want %q %q", test.line, keyword, args, test.keyword, test.args)
+		}
+	}
+
+	if _, _, err := splitLine(`HostName "unterminated`); err == nil {
+		t.Errorf("splitLine should fail on an unterminated quote")
+	}
+}
+
+var patternConfig = `
+Host web1 web1.prod
+	HostName 10.0.0.1
+
+Host *.internal
+	User admin
+
+Host * !bastion
+	Port 2222
+
+Host bastion
+`
+
+func TestParsingPatterns(t *testing.T) {
+	configs, err := Parse(patternConfig)
+
+	if err != nil {
+		t.Fatalf("Parsing failed: %v", err)
+	}
+
+	var names []string
+	for _, c := range configs {
+		names = append(names, c.Name)
+	}
+
+	if got := strings.Join(names, ","); got != "web1,web1.prod,bastion" {
+		t.Fatalf("Parsing config file failed: got %v, want %v\n", got, "web1,web1.prod,bastion")
+	}
+
+	if configs[1].Host != "10.0.0.1" {
+		t.Errorf("Parsing config file failed: got %v, want %v\n", configs[1].Host, "10.0.0.1")
+	}
+
+	if configs[2].Host != "bastion" {
+		t.Errorf("Parsing config file failed: got %v, want %v\n", configs[2].Host, "bastion")
+	}
+
+	if configs[2].SourceLine != 11 || configs[2].SourceFile != GetConfigPath() {
+		t.Errorf("Parsing config file failed: got source %v:%v, want %v:%v\n", configs[2].SourceFile, configs[2].SourceLine, GetConfigPath(), 11)
+	}
+}
+
+var inheritedConfig = `
+User global
+
+Host db1.internal
+	HostName 10.0.0.5
+	Port 2200
+
+Host *.internal
+	User admin
+	Port 22
+	IdentityFile ~/.ssh/internal
+
+Host !db1.internal *
+	IdentityFile ~/.ssh/default
+
+Match originalhost web1
+	User www
+
+Host web1
+	HostName web1.example.com
+`
+
+func TestParsingInheritance(t *testing.T) {
+	configs, err := Parse(inheritedConfig)
+
+	if err != nil {
+		t.Fatalf("Parsing failed: %v", err)
+	}
+
+	if len(configs) != 2 {
+		t.Fatalf("Parsing config file failed: got %v, want %v\n", len(configs), 2)
+	}
+
+	want := SSHConfig{Name: "db1.internal", Host: "10.0.0.5", Port: "2200", User: "global", Key: filepath.Join(HomeDir(), ".ssh/internal")}
+	if !sameColumns(configs[0], want) {
+		t.Errorf("Parsing config file failed: got %+v, want %+v\n", configs[0], want)
+	}
+
+	want = SSHConfig{Name: "web1", Host: "web1.example.com", User: "global", Key: filepath.Join(HomeDir(), ".ssh/default")}
+	if !sameColumns(configs[1], want) {
+		t.Errorf("Parsing config file failed: got %+v, want %+v\n", configs[1], want)
+	}
+}
+
+func TestMatchHost(t *testing.T) {
+	tests := []struct {
+		alias    string
+		patterns []string
+		want     bool
+	}{
+		{"web1", []string{"web1"}, true},
+		{"WEB1", []string{"web?"}, true},
+		{"db.corp", []string{"*.corp"}, true},
+		{"bastion", []string{"*", "!bastion"}, false},
+		{"web1", []string{"*", "!bastion"}, true},
+		{"web1", []string{"!web2"}, false},
+		{"web[1]", []string{"web[1]"}, true},
+	}
+
+	for _, test := range tests {
+		if got := matchHost(test.alias, test.patterns); got != test.want {
+			t.Errorf("matchHost(%q, %q): got %v, want %v", test.alias, test.patterns, got, test.want)
+		}
+	}
+}
+
+func sameColumns(got SSHConfig, want SSHConfig) bool {
+	return got.Name == want.Name && got.Host == want.Host && got.Port == want.Port &&
+		got.User == want.User && got.Key == want.Key
+}
+
+func TestParseResolved(t *testing.T) {
+	output := "host web1\nuser deploy\nhostname 10.0.0.1\nport 2222\n" +
+		"identityfile ~/.ssh/id_ed25519\nidentityfile ~/.ssh/id_rsa\nproxyjump bastion\n"
+
+	c := parseResolved("web1", []byte(output))
+
+	want := SSHConfig{Name: "web1", Host: "10.0.0.1", Port: "2222", User: "deploy", Key: filepath.Join(HomeDir(), ".ssh/id_ed25519")}
+	if !sameColumns(c, want) {
+		t.Errorf("Parsing ssh -G output failed: got %+v, want %+v\n", c, want)
+	}
+
+	if len(c.IdentityFiles) != 2 || c.ProxyJump != "bastion" {
+		t.Errorf("Parsing ssh -G output failed: got %v %v\n", c.IdentityFiles, c.ProxyJump)
+	}
+}
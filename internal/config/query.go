@@ -0,0 +1,156 @@
+package config
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+
+	"github.com/byawitz/ggh/internal/fuzzy"
+)
+
+// Query is a parsed search such as "tag:prod env:eu web". Words of the form
+// key:value compare a single field: tag, user, host, name, port or the label
+// called key. Other words are matched fuzzily against the name, host, user
+// and tags, or as a substring anywhere else, see SSHConfig.Matches. A host
+// matches the query when it matches every word.
+type Query []queryTerm
+
+// Fields free words are matched against, as keys of Match.Positions. The
+// positions for FieldTags index the tags joined by commas.
+const (
+	FieldName = "name"
+	FieldHost = "host"
+	FieldUser = "user"
+	FieldTags = "tags"
+)
+
+// nameBonus ranks a host whose alias matches above one that only matches
+// through its other fields.
+const nameBonus = 8
+
+// Match tells how well a host matches a query.
+type Match struct {
+	Score int
+	// Positions holds the rune indices of the matched characters per field.
+	Positions map[string][]int
+}
+
+type queryTerm struct {
+	key   string
+	value string
+}
+
+func ParseQuery(query string) Query {
+	var q Query
+
+	for _, word := range strings.Fields(query) {
+		key, value, found := strings.Cut(word, ":")
+		if found && value != "" && isQueryKey(key) {
+			q = append(q, queryTerm{key: strings.ToLower(key), value: value})
+			continue
+		}
+
+		q = append(q, queryTerm{value: word})
+	}
+
+	return q
+}
+
+func isQueryKey(key string) bool {
+	if key == "" {
+		return false
+	}
+
+	for _, r := range key {
+		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') && r != '_' && r != '-' {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (q Query) Matches(c SSHConfig) bool {
+	_, ok := q.Match(c)
+	return ok
+}
+
+// Match scores c against every word of the query. The score of a word is
+// the best fuzzy match among the fields; field filters do not add to it.
+func (q Query) Match(c SSHConfig) (Match, bool) {
+	m := Match{Positions: map[string][]int{}}
+
+	for _, term := range q {
+		if term.key != "" {
+			if !term.matches(c) {
+				return Match{}, false
+			}
+			continue
+		}
+
+		score, field, positions, ok := term.fuzzy(c)
+		if !ok {
+			// Descriptions and labels are prose rather than names, so they
+			// are searched for the word as is.
+			if !c.Matches(term.value) {
+				return Match{}, false
+			}
+			score = 1
+		}
+
+		m.Score += score
+		if field != "" {
+			m.Positions[field] = mergePositions(m.Positions[field], positions)
+		}
+	}
+
+	return m, true
+}
+
+func (t queryTerm) fuzzy(c SSHConfig) (score int, field string, positions []int, ok bool) {
+	for _, f := range []struct {
+		name  string
+		value string
+		bonus int
+	}{
+		{FieldName, c.Name, nameBonus},
+		{FieldHost, c.Host, 0},
+		{FieldUser, c.User, 0},
+		{FieldTags, strings.Join(c.Tags, ","), 0},
+	} {
+		s, p, matched := fuzzy.Match(t.value, f.value)
+		if matched && (!ok || s+f.bonus > score) {
+			score, field, positions, ok = s+f.bonus, f.name, p, true
+		}
+	}
+
+	return score, field, positions, ok
+}
+
+func mergePositions(a []int, b []int) []int {
+	merged := append(slices.Clone(a), b...)
+	slices.Sort(merged)
+	return slices.Compact(merged)
+}
+
+func (t queryTerm) matches(c SSHConfig) bool {
+	contains := func(s string) bool {
+		return strings.Contains(strings.ToLower(s), strings.ToLower(t.value))
+	}
+
+	switch t.key {
+	case "tag":
+		return slices.ContainsFunc(c.Tags, func(tag string) bool { return strings.EqualFold(tag, t.value) })
+	case "user":
+		return strings.EqualFold(c.User, t.value)
+	case "host":
+		return contains(c.Host)
+	case "name":
+		return contains(c.Name)
+	case "port":
+		return cmp.Or(c.Port, "22") == t.value
+	}
+
+	value, ok := c.Labels[t.key]
+	return ok && strings.EqualFold(value, t.value)
+}
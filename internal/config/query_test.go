@@ -0,0 +1,71 @@
+package config
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestQuery(t *testing.T) {
+	c := SSHConfig{
+		Name:   "db1",
+		Host:   "10.0.0.5",
+		User:   "root",
+		Tags:   []string{"prod", "db"},
+		Labels: map[string]string{"env": "eu"},
+	}
+
+	tests := []struct {
+		query string
+		match bool
+	}{
+		{"", true},
+		{"tag:prod", true},
+		{"tag:PROD env:eu", true},
+		{"tag:pro", false},
+		{"env:us", false},
+		{"user:root db", true},
+		{"user:admin", false},
+		{"port:22 host:10.0", true},
+		{"owner:dba", false},
+		{"10.0.0.5:22", false},
+		{"tag:prod web", false},
+	}
+
+	for _, tt := range tests {
+		if got := ParseQuery(tt.query).Matches(c); got != tt.match {
+			t.Errorf("ParseQuery(%q).Matches() = %v, want %v", tt.query, got, tt.match)
+		}
+	}
+}
+
+func TestQueryRanking(t *testing.T) {
+	hosts := []SSHConfig{
+		{Name: "web", Host: "10.0.0.1"},
+		{Name: "web-eu-1", Host: "10.0.0.2"},
+		{Name: "api", Host: "web.example.com"},
+		{Name: "misc", Host: "10.0.0.3", User: "dev-web"},
+	}
+
+	q := ParseQuery("web")
+	previous := 0
+	for i, c := range hosts {
+		m, ok := q.Match(c)
+		if !ok {
+			t.Fatalf("%q did not match %q", "web", c.Name)
+		}
+
+		if i > 0 && m.Score >= previous {
+			t.Errorf("%q scored %d, not below %q (%d)", c.Name, m.Score, hosts[i-1].Name, previous)
+		}
+		previous = m.Score
+	}
+
+	m, _ := ParseQuery("pgeu").Match(SSHConfig{Name: "postgres-eu-1", Host: "db.internal"})
+	if got := m.Positions[FieldName]; !slices.Equal(got, []int{0, 4, 9, 10}) {
+		t.Errorf("Positions[%q] = %v, want [0 4 9 10]", FieldName, got)
+	}
+
+	if _, ok := ParseQuery("xyz").Match(hosts[0]); ok {
+		t.Errorf("%q matched %q", "xyz", hosts[0].Name)
+	}
+}
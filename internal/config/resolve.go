@@ -0,0 +1,109 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+)
+
+const resolveWorkers = 8
+
+var resolved = struct {
+	sync.Mutex
+	cache map[string]SSHConfig
+}{cache: map[string]SSHConfig{}}
+
+// Resolve asks ssh itself for the effective configuration of alias by running
+// "ssh -G". Unlike the built-in parser it honours every Match criterion and
+// canonicalization rule. Results are cached for the lifetime of the process.
+func Resolve(alias string) (SSHConfig, error) {
+	resolved.Lock()
+	c, ok := resolved.cache[alias]
+	resolved.Unlock()
+
+	if ok {
+		return c, nil
+	}
+
+	output, err := exec.Command("ssh", WithConfigFlag([]string{"-G", alias})...).Output()
+	if err != nil {
+		return SSHConfig{}, fmt.Errorf("ssh -G %s: %w", alias, err)
+	}
+
+	c = parseResolved(alias, output)
+
+	resolved.Lock()
+	resolved.cache[alias] = c
+	resolved.Unlock()
+
+	return c, nil
+}
+
+// WithConfigFlag prepends "-F file" to the arguments of an ssh invocation
+// when a custom configuration is in use, so ssh reads the same file as ggh.
+func WithConfigFlag(args []string) []string {
+	if custom := CustomConfigPath(); custom != "" {
+		return append([]string{"-F", custom}, args...)
+	}
+
+	return args
+}
+
+// Forget drops every cached resolution, for instance after the config files
+// were edited.
+func Forget() {
+	resolved.Lock()
+	resolved.cache = map[string]SSHConfig{}
+	resolved.Unlock()
+}
+
+// ResolveAll resolves every entry of list through ssh. Entries ssh fails to
+// resolve keep the values found by the built-in parser.
+func ResolveAll(list []SSHConfig) []SSHConfig {
+	result := make([]SSHConfig, len(list))
+	copy(result, list)
+
+	var wg sync.WaitGroup
+	workers := make(chan struct{}, resolveWorkers)
+
+	for i, c := range list {
+		wg.Add(1)
+		workers <- struct{}{}
+
+		go func() {
+			defer wg.Done()
+			defer func() { <-workers }()
+
+			if r, err := Resolve(c.Name); err == nil {
+				// ssh -G knows nothing of what only the parser reads, which
+				// has to be carried over for every such field.
+				r.SourceFile, r.SourceLine, r.Origin = c.SourceFile, c.SourceLine, c.Origin
+				r.Tags, r.Description, r.Labels = c.Tags, c.Description, c.Labels
+				result[i] = r
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	return result
+}
+
+func parseResolved(alias string, output []byte) SSHConfig {
+	opts := options{}
+
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		keyword, value, _ := strings.Cut(strings.TrimSpace(scanner.Text()), " ")
+		if keyword == "" {
+			continue
+		}
+
+		opts[keyword] = append(opts[keyword], value)
+	}
+
+	return newSSHConfig(alias, opts)
+}
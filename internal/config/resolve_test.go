@@ -0,0 +1,37 @@
+package config
+
+import (
+	"os/exec"
+	"slices"
+	"testing"
+)
+
+func TestResolveAllKeepsParsedFields(t *testing.T) {
+	if _, err := exec.LookPath("ssh"); err != nil {
+		t.Skip("ssh is not installed")
+	}
+
+	t.Setenv("HOME", t.TempDir())
+	path := writeSshFile(t, "config", "Host db1\n\t# ggh: tags=prod,db description=\"Main database\" team=data\n\tHostName db1.com\n")
+	SetConfigPath(path)
+	t.Cleanup(func() { SetConfigPath(""); Forget() })
+
+	configs, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := ResolveAll(configs)
+	if len(r) != 1 || r[0].Host != "db1.com" {
+		t.Fatalf("ResolveAll() = %+v, want db1", r)
+	}
+
+	c := r[0]
+	if c.SourceFile != path || c.SourceLine != 1 || c.Origin != OriginUser {
+		t.Errorf("ResolveAll() lost the source: %s:%d (%s)", c.SourceFile, c.SourceLine, c.Origin)
+	}
+
+	if !slices.Equal(c.Tags, []string{"prod", "db"}) || c.Description != "Main database" || c.Labels["team"] != "data" {
+		t.Errorf("ResolveAll() lost the annotations: %q, %q, %v", c.Tags, c.Description, c.Labels)
+	}
+}
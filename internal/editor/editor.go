@@ -0,0 +1,55 @@
+package editor
+
+import (
+	"cmp"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// Command builds the command that opens file at line in the user's editor,
+// taken from $VISUAL or $EDITOR.
+func Command(file string, line int) *exec.Cmd {
+	fields := strings.Fields(cmp.Or(os.Getenv("VISUAL"), os.Getenv("EDITOR")))
+	if len(fields) == 0 {
+		fields = []string{defaultEditor()}
+	}
+
+	name := strings.TrimSuffix(filepath.Base(fields[0]), ".exe")
+	args := fields[1:]
+
+	switch name {
+	case "code", "code-insiders", "codium":
+		args = append(args, "--wait", "--goto", fmt.Sprintf("%s:%d", file, line))
+	case "subl", "zed", "hx", "helix":
+		args = append(args, fmt.Sprintf("%s:%d", file, line))
+	case "notepad":
+		args = append(args, file)
+	default:
+		// vi, vim, nvim, nano, emacs, micro, kak and most other terminal
+		// editors understand "+line file".
+		args = append(args, fmt.Sprintf("+%d", line), file)
+	}
+
+	cmd := exec.Command(fields[0], args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	return cmd
+}
+
+func Open(file string, line int) error {
+	return Command(file, line).Run()
+}
+
+func defaultEditor() string {
+	if runtime.GOOS == "windows" {
+		return "notepad"
+	}
+
+	return "vi"
+}
@@ -0,0 +1,125 @@
+// Package fuzzy implements fzf style matching: the characters of a pattern
+// must appear in a text in order, but not necessarily next to each other.
+package fuzzy
+
+import (
+	"math"
+	"strings"
+	"unicode"
+)
+
+const (
+	scoreMatch          = 16
+	bonusFirst          = 12
+	bonusBoundary       = 10
+	bonusConsecutive    = 8
+	penaltyGapStart     = 3
+	penaltyGapExtension = 1
+)
+
+// Match reports whether pattern matches text and how well. The score rewards
+// matches at the start of words and runs of consecutive characters, and
+// penalizes gaps between them and unmatched characters. Positions are the
+// rune indices of the matched characters in text, chosen to give the best
+// score. Matching ignores case unless pattern has upper case characters.
+func Match(pattern string, text string) (score int, positions []int, ok bool) {
+	p := []rune(pattern)
+	t := []rune(text)
+
+	if len(p) == 0 {
+		return 0, nil, true
+	}
+
+	fold := func(r rune) rune { return r }
+	if strings.ToLower(pattern) == pattern {
+		fold = unicode.ToLower
+	}
+
+	// best[i][j] is the best score of matching p[:i+1] with p[i] at t[j],
+	// from[i][j] the position of p[i-1] on that path and run[i][j] the bonus
+	// consecutive characters after t[j] inherit.
+	best := make([][]int, len(p))
+	from := make([][]int, len(p))
+	run := make([][]int, len(p))
+
+	for i := range p {
+		best[i] = make([]int, len(t))
+		from[i] = make([]int, len(t))
+		run[i] = make([]int, len(t))
+
+		for j := range t {
+			best[i][j] = noMatch
+			if fold(t[j]) != p[i] {
+				continue
+			}
+
+			bonus := bonusAt(t, j)
+			if i == 0 {
+				best[i][j], from[i][j], run[i][j] = scoreMatch+bonus, -1, bonus
+				continue
+			}
+
+			for k := i - 1; k < j; k++ {
+				if best[i-1][k] == noMatch {
+					continue
+				}
+
+				s, r := best[i-1][k]+scoreMatch, bonus
+				if k == j-1 {
+					r = max(bonus, run[i-1][k], bonusConsecutive)
+					s += r
+				} else {
+					s += bonus - penaltyGapStart - penaltyGapExtension*(j-k-2)
+				}
+
+				if s > best[i][j] {
+					best[i][j], from[i][j], run[i][j] = s, k, r
+				}
+			}
+		}
+	}
+
+	last := len(p) - 1
+	end := -1
+	for j := range t {
+		if best[last][j] != noMatch && (end == -1 || best[last][j] > best[last][end]) {
+			end = j
+		}
+	}
+
+	if end == -1 {
+		return 0, nil, false
+	}
+
+	positions = make([]int, len(p))
+	for i, j := last, end; i >= 0; i-- {
+		positions[i] = j
+		j = from[i][j]
+	}
+
+	// Prefer "web" over "web-eu-1" when both match equally well.
+	return best[last][end] - (len(t)-len(p))/4, positions, true
+}
+
+const noMatch = math.MinInt / 2
+
+func bonusAt(t []rune, i int) int {
+	switch {
+	case i == 0:
+		return bonusFirst
+	case boundary(t[i-1], t[i]):
+		return bonusBoundary
+	}
+
+	return 0
+}
+
+// boundary reports whether r starts a new word after prev, as in "db-eu",
+// "db.eu" or "dbEu".
+func boundary(prev rune, r rune) bool {
+	if strings.ContainsRune(" -_./@:,", prev) {
+		return true
+	}
+
+	return unicode.IsLower(prev) && unicode.IsUpper(r) || !unicode.IsDigit(prev) && unicode.IsDigit(r)
+}
@@ -0,0 +1,56 @@
+package fuzzy
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		pattern   string
+		text      string
+		ok        bool
+		positions []int
+	}{
+		{"", "anything", true, nil},
+		{"pgeu", "postgres-eu-1", true, []int{0, 4, 9, 10}},
+		{"db1", "prod-DB-01", true, []int{5, 6, 9}},
+		{"DB", "prod-db-01", false, nil},
+		{"ba", "abc", false, nil},
+		// The shortest window is preferred over the first occurrence.
+		{"web", "w-e-web", true, []int{4, 5, 6}},
+	}
+
+	for _, tt := range tests {
+		_, positions, ok := Match(tt.pattern, tt.text)
+		if ok != tt.ok || !slices.Equal(positions, tt.positions) {
+			t.Errorf("Match(%q, %q) = %v, %v, want %v, %v", tt.pattern, tt.text, positions, ok, tt.positions, tt.ok)
+		}
+	}
+}
+
+func TestMatchRanking(t *testing.T) {
+	// Each text should score higher than the next one for the pattern.
+	tests := []struct {
+		pattern string
+		texts   []string
+	}{
+		{"web", []string{"web", "web-eu-1", "api-web", "w-e-b", "wide-enterprise-box"}},
+		{"db", []string{"db1", "prod-db", "dashboard"}},
+	}
+
+	for _, tt := range tests {
+		previous := 0
+		for i, text := range tt.texts {
+			score, _, ok := Match(tt.pattern, text)
+			if !ok {
+				t.Fatalf("Match(%q, %q) did not match", tt.pattern, text)
+			}
+
+			if i > 0 && score >= previous {
+				t.Errorf("Match(%q, %q) scored %d, not below %q (%d)", tt.pattern, text, score, tt.texts[i-1], previous)
+			}
+			previous = score
+		}
+	}
+}
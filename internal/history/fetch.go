@@ -1,18 +1,32 @@
 package history
 
 import (
+	"cmp"
 	"encoding/json"
 	"fmt"
 	"github.com/byawitz/ggh/internal/config"
 	"github.com/byawitz/ggh/internal/theme"
 	"github.com/charmbracelet/bubbles/table"
 	"log"
+	"slices"
 	"time"
 )
 
 type SSHHistory struct {
 	Connection config.SSHConfig `json:"connection"`
 	Date       time.Time        `json:"date"`
+	Count      int              `json:"count,omitempty"`
+	// Args is the last ssh command line used for the connection, in the
+	// normal form of command.SSHArgs.Argv, for replaying it.
+	Args     []string  `json:"args,omitempty"`
+	Sessions []Session `json:"sessions,omitempty"`
+}
+
+// Session is one run of ssh for a history entry.
+type Session struct {
+	Date     time.Time     `json:"date"`
+	Duration time.Duration `json:"duration"`
+	ExitCode int           `json:"exit_code"`
 }
 
 func FetchWithDefaultFile() ([]SSHHistory, error) {
@@ -31,7 +45,7 @@ func Fetch(file []byte) ([]SSHHistory, error) {
 		return nil, err
 	}
 
-	search, err := config.ParseWithSearch("", config.GetConfigFile())
+	search, err := config.Load()
 
 	if err != nil {
 		return historyList, nil
@@ -39,8 +53,14 @@ func Fetch(file []byte) ([]SSHHistory, error) {
 
 	for i, history := range historyList {
 		for _, sshConfig := range search {
-			if sshConfig.Host == history.Connection.Host {
+			if mapsTo(history.Connection, sshConfig) {
 				historyList[i].Connection.Name = sshConfig.Name
+				historyList[i].Connection.SourceFile = sshConfig.SourceFile
+				historyList[i].Connection.SourceLine = sshConfig.SourceLine
+				historyList[i].Connection.Tags = sshConfig.Tags
+				historyList[i].Connection.Description = sshConfig.Description
+				historyList[i].Connection.Labels = sshConfig.Labels
+				break
 			}
 		}
 	}
@@ -48,17 +68,23 @@ func Fetch(file []byte) ([]SSHHistory, error) {
 	return historyList, nil
 }
 
-func Print() {
+// Print lists the history entries matching query, see config.Query.
+func Print(query string) {
 	list, err := FetchWithDefaultFile()
 
 	if err != nil {
 		log.Fatal(err)
 	}
 
+	q := config.ParseQuery(query)
+	list = slices.DeleteFunc(list, func(h SSHHistory) bool { return !q.Matches(h.Connection) })
+
 	if len(list) == 0 {
 		fmt.Println("No history found.")
 		return
 	}
+	hosts, _ := config.Load()
+
 	var rows []table.Row
 	currentTime := time.Now()
 	for _, history := range list {
@@ -66,7 +92,10 @@ func Print() {
 			history.Connection.Host,
 			history.Connection.Port,
 			history.Connection.User,
-			history.Connection.Key,
+			config.ShortPath(history.Connection.Key),
+			history.Connection.Jump(hosts),
+			history.Connection.Forwards(),
+			history.Connection.Annotations(),
 			fmt.Sprintf("%s", ReadableTime(currentTime.Sub(history.Date))),
 		})
 	}
@@ -74,6 +103,81 @@ func Print() {
 	fmt.Println(theme.PrintTable(rows, theme.PrintHistory))
 }
 
+// mapsTo reports whether the connection c reached the host defined by alias:
+// the same host, and the same user and port when c was made with them. The
+// first alias that matches wins, like the first Host block in ssh_config.
+func mapsTo(c config.SSHConfig, alias config.SSHConfig) bool {
+	if c.Name != "" {
+		return c.Name == alias.Name
+	}
+
+	return c.Host == alias.Host &&
+		(c.User == "" || c.User == alias.User) &&
+		(c.Port == "" || c.Port == cmp.Or(alias.Port, "22"))
+}
+
+// LastUsed returns when c was last connected to, if ever.
+func LastUsed(list []SSHHistory, c config.SSHConfig) (time.Time, bool) {
+	var last time.Time
+
+	for _, item := range list {
+		if item.Connection.Host == c.Host && item.Connection.Name == c.Name && item.Date.After(last) {
+			last = item.Date
+		}
+	}
+
+	return last, !last.IsZero()
+}
+
+// Sessions returns the last n sessions to c, most recent first.
+func Sessions(list []SSHHistory, c config.SSHConfig, n int) []Session {
+	var sessions []Session
+
+	for _, item := range list {
+		if item.Connection.Host == c.Host && item.Connection.Name == c.Name {
+			sessions = append(sessions, item.Sessions...)
+		}
+	}
+
+	slices.SortStableFunc(sessions, func(a, b Session) int {
+		return b.Date.Compare(a.Date)
+	})
+
+	return sessions[:min(n, len(sessions))]
+}
+
+// Boost ranks a host higher the more recently and the more often it was
+// connected to, for ordering search results.
+func Boost(list []SSHHistory, c config.SSHConfig, now time.Time) int {
+	boost := 0
+
+	for _, item := range list {
+		if item.Connection.Host != c.Host || item.Connection.Name != c.Name {
+			continue
+		}
+
+		switch age := now.Sub(item.Date); {
+		case age < time.Hour:
+			boost += 30
+		case age < 24*time.Hour:
+			boost += 20
+		case age < 7*24*time.Hour:
+			boost += 10
+		case age < 30*24*time.Hour:
+			boost += 5
+		}
+
+		boost += 2 * min(max(item.Count, 1), 10)
+	}
+
+	return boost
+}
+
+// ReadableDuration formats how long a session lasted, e.g. "1h2m5s".
+func ReadableDuration(d time.Duration) string {
+	return d.Round(time.Second).String()
+}
+
 func ReadableTime(d time.Duration) string {
 	if d.Seconds() < 60 {
 		return fmt.Sprintf("%d seconds ago", int(d.Seconds()))
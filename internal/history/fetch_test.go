@@ -2,6 +2,9 @@ package history
 
 import (
 	"testing"
+	"time"
+
+	"github.com/byawitz/ggh/internal/config"
 )
 
 var historyFile = `
@@ -53,3 +56,57 @@ func TestParsing(t *testing.T) {
 		t.Errorf("Parsing config file failed: got %v, want %v\n", history[1].Connection.Host, "host.name")
 	}
 }
+
+func TestLastUsed(t *testing.T) {
+	now := time.Now()
+	list := []SSHHistory{
+		{Connection: config.SSHConfig{Name: "db1", Host: "10.0.0.1"}, Date: now.Add(-time.Hour)},
+		{Connection: config.SSHConfig{Host: "10.0.0.1"}, Date: now},
+		{Connection: config.SSHConfig{Name: "db1", Host: "10.0.0.1"}, Date: now.Add(-2 * time.Hour)},
+	}
+
+	last, ok := LastUsed(list, config.SSHConfig{Name: "db1", Host: "10.0.0.1"})
+	if !ok || !last.Equal(now.Add(-time.Hour)) {
+		t.Errorf("LastUsed() = %v, %v, want %v, true", last, ok, now.Add(-time.Hour))
+	}
+
+	if _, ok := LastUsed(list, config.SSHConfig{Name: "web1", Host: "10.0.0.2"}); ok {
+		t.Errorf("LastUsed() found a host that was never connected to")
+	}
+}
+
+func TestMapsTo(t *testing.T) {
+	alias := config.SSHConfig{Name: "db1", Host: "10.0.0.1", User: "root"}
+
+	tests := []struct {
+		connection config.SSHConfig
+		want       bool
+	}{
+		{config.SSHConfig{Host: "10.0.0.1"}, true},
+		{config.SSHConfig{Host: "10.0.0.1", User: "root", Port: "22"}, true},
+		{config.SSHConfig{Host: "10.0.0.1", User: "deploy"}, false},
+		{config.SSHConfig{Host: "10.0.0.1", Port: "2222"}, false},
+		{config.SSHConfig{Name: "db2", Host: "10.0.0.1"}, false},
+	}
+
+	for _, tt := range tests {
+		if got := mapsTo(tt.connection, alias); got != tt.want {
+			t.Errorf("mapsTo(%+v) = %v, want %v", tt.connection, got, tt.want)
+		}
+	}
+}
+
+func TestSessions(t *testing.T) {
+	now := time.Now()
+	c := config.SSHConfig{Name: "db1", Host: "10.0.0.1"}
+	list := []SSHHistory{
+		{Connection: c, Sessions: []Session{{Date: now, ExitCode: 0}, {Date: now.Add(-2 * time.Hour), ExitCode: 255}}},
+		{Connection: config.SSHConfig{Host: "10.0.0.2"}, Sessions: []Session{{Date: now}}},
+		{Connection: c, Sessions: []Session{{Date: now.Add(-time.Hour), ExitCode: 130}}},
+	}
+
+	sessions := Sessions(list, c, 2)
+	if len(sessions) != 2 || sessions[0].ExitCode != 0 || sessions[1].ExitCode != 130 {
+		t.Errorf("Sessions() = %+v, want the sessions exiting with 0 and 130", sessions)
+	}
+}
@@ -3,55 +3,147 @@ package history
 import (
 	"encoding/json"
 	"fmt"
+	"github.com/byawitz/ggh/internal/command"
 	"github.com/byawitz/ggh/internal/config"
 	"github.com/charmbracelet/bubbles/table"
 	"os"
 	"slices"
-	"strings"
 	"time"
 )
 
-func AddHistoryFromArgs(args []string) {
-	if len(args) == 1 && !strings.Contains(args[0], "@") {
-		localConfig, err := config.GetConfig(args[0])
-		if err != nil || localConfig.Name == "" {
-			return
+// maxSessions is how many sessions are kept per history entry.
+const maxSessions = 10
+
+// AddHistoryFromArgs records the connection made with the ssh arguments args
+// and returns it, so its session can be recorded once it ended.
+func AddHistoryFromArgs(args []string) config.SSHConfig {
+	a, err := command.ParseSSH(args)
+	if err != nil || a.Destination == "" {
+		return config.SSHConfig{}
+	}
+
+	// The configuration the connection was made with is recorded with it,
+	// as an absolute path, so replaying it from any directory or shell reads
+	// the same file.
+	for i, f := range a.Flags {
+		if f.Name == "F" {
+			a.Flags[i].Value = config.AbsConfigPath(f.Value)
 		}
+	}
+	if custom := config.CustomConfigPath(); custom != "" && !a.Has("F") {
+		a.Flags = append([]command.Flag{{Name: "F", Value: custom}}, a.Flags...)
+	}
 
-		AddHistory(localConfig)
-		return
+	// A destination naming an alias is recorded as the host it stands for,
+	// with the options of the command line on top.
+	base := config.SSHConfig{Host: a.Destination}
+	if localConfig, err := config.GetConfig(a.Destination); err == nil && localConfig.Name != "" {
+		base = localConfig
 	}
 
-	generatedConfig := config.SSHConfig{}
+	c := connection(a, base)
+	AddHistory(c, a.Argv())
+	return c
+}
 
-	skipNext := false
-	for i, arg := range args {
-		if skipNext {
-			skipNext = false
-			continue
+// connection builds the history entry of a connection made with a to base,
+// the host of the ssh config a names or just the destination.
+func connection(a command.SSHArgs, base config.SSHConfig) config.SSHConfig {
+	generatedConfig := base
+
+	if a.User != "" {
+		generatedConfig.User = a.User
+	}
+
+	if a.Port != "" {
+		generatedConfig.Port = a.Port
+	}
+
+	if hostname, ok := a.Option("hostname"); ok {
+		generatedConfig.Host = hostname
+	}
+
+	// ssh tries the identity files of the command line before the ones of
+	// the config.
+	identityFiles := a.Values("i")
+	if identityFile, ok := a.Option("identityfile"); ok {
+		identityFiles = append(identityFiles, identityFile)
+	}
+	if len(identityFiles) > 0 {
+		for _, f := range base.IdentityFiles {
+			if !slices.Contains(identityFiles, f) {
+				identityFiles = append(identityFiles, f)
+			}
 		}
+		generatedConfig.Key = identityFiles[0]
+		generatedConfig.IdentityFiles = identityFiles
+	}
+
+	if jumps := a.Values("J"); len(jumps) > 0 {
+		generatedConfig.ProxyJump = jumps[0]
+	} else if jump, ok := a.Option("proxyjump"); ok {
+		generatedConfig.ProxyJump = jump
+	}
+
+	if proxyCommand, ok := a.Option("proxycommand"); ok {
+		generatedConfig.ProxyCommand = proxyCommand
+	}
+
+	if forwardAgent, ok := a.Option("forwardagent"); ok {
+		generatedConfig.ForwardAgent = forwardAgent
+	}
 
-		switch {
-		case strings.HasPrefix(arg, "-p"):
-			if arg == "-p" {
-				generatedConfig.Port = args[i+1]
-				skipNext = true
-			} else {
-				generatedConfig.Port = args[i][2:]
+	// The forwards of the config are cloned, so appending to them never
+	// writes into the slices of base.
+	generatedConfig.LocalForward = slices.Clone(base.LocalForward)
+	generatedConfig.RemoteForward = slices.Clone(base.RemoteForward)
+	generatedConfig.DynamicForward = slices.Clone(base.DynamicForward)
+
+	for _, f := range a.Flags {
+		switch f.Name {
+		case "A":
+			generatedConfig.ForwardAgent = "yes"
+		case "a":
+			generatedConfig.ForwardAgent = ""
+		case "L":
+			generatedConfig.LocalForward = append(generatedConfig.LocalForward, configForward(f.Value))
+		case "R":
+			generatedConfig.RemoteForward = append(generatedConfig.RemoteForward, configForward(f.Value))
+		case "D":
+			generatedConfig.DynamicForward = append(generatedConfig.DynamicForward, f.Value)
+		}
+	}
+
+	return generatedConfig
+}
+
+// configForward turns a -L or -R argument such as "8080:db:5432" into the
+// "8080 db:5432" form used by ssh_config.
+func configForward(spec string) string {
+	depth, colons := 0, []int{}
+	for i, c := range spec {
+		switch c {
+		case '[':
+			depth++
+		case ']':
+			depth--
+		case ':':
+			if depth == 0 {
+				colons = append(colons, i)
 			}
-		case arg == "-i":
-			generatedConfig.Key = args[i+1]
-			skipNext = true
-		case strings.Contains(arg, "@"):
-			values := strings.Split(arg, "@")
-			generatedConfig.User = values[0]
-			generatedConfig.Host = values[1]
 		}
 	}
-	AddHistory(generatedConfig)
+
+	if len(colons) < 2 {
+		return spec
+	}
+
+	split := colons[len(colons)-2]
+	return spec[:split] + " " + spec[split+1:]
 }
 
-func AddHistory(c config.SSHConfig) {
+// AddHistory records a connection to c made with the ssh arguments args.
+func AddHistory(c config.SSHConfig, args []string) {
 	if c.Host == "" {
 		return
 	}
@@ -63,13 +155,43 @@ func AddHistory(c config.SSHConfig) {
 		return
 	}
 
-	err = saveFile(SSHHistory{Connection: c, Date: time.Now()}, list)
+	err = saveFile(SSHHistory{Connection: c, Date: time.Now(), Args: args}, list)
 	if err != nil {
 		fmt.Println("error saving ggh file")
 		return
 	}
 }
 
+// AddSession records a session to c, which AddHistory saved before.
+func AddSession(c config.SSHConfig, session Session) {
+	if c.Host == "" {
+		return
+	}
+
+	list, err := Fetch(getFile())
+
+	if err != nil {
+		fmt.Println("error getting ggh file")
+		return
+	}
+
+	// Fetch may have named the entry after its alias since it was saved.
+	i := slices.IndexFunc(list, func(h SSHHistory) bool {
+		return h.Connection.Host == c.Host && (c.Name == "" || h.Connection.Name == c.Name)
+	})
+	if i == -1 {
+		return
+	}
+
+	sessions := append([]Session{session}, list[i].Sessions...)
+	list[i].Sessions = sessions[:min(len(sessions), maxSessions)]
+
+	err = saveFile(SSHHistory{}, list)
+	if err != nil {
+		fmt.Println("error saving ggh file")
+	}
+}
+
 func RemoveByIP(row table.Row) {
 	list, err := Fetch(getFile())
 
@@ -97,6 +219,23 @@ func RemoveByIP(row table.Row) {
 
 }
 
+// Relink names every history entry of c's connection after alias, once the
+// connection got its own Host block.
+func Relink(c config.SSHConfig, alias string) error {
+	list, err := Fetch(getFile())
+	if err != nil {
+		return err
+	}
+
+	for i, item := range list {
+		if item.Connection.Host == c.Host && item.Connection.Name == c.Name {
+			list[i].Connection.Name = alias
+		}
+	}
+
+	return saveFile(SSHHistory{}, list)
+}
+
 func saveFile(n SSHHistory, l []SSHHistory) error {
 	file := getFileLocation()
 	fileContent := stringify(n, l)
@@ -109,9 +248,13 @@ func saveFile(n SSHHistory, l []SSHHistory) error {
 func stringify(n SSHHistory, l []SSHHistory) string {
 	history := make([]SSHHistory, 0)
 
+	// Entries written before connections were counted stand for at least one.
+	n.Count = 1
 	for i, sshHistory := range l {
 		if sshHistory.Connection.Host == n.Connection.Host &&
 			sshHistory.Connection.Name == n.Connection.Name {
+			n.Count = max(sshHistory.Count, 1) + 1
+			n.Sessions = sshHistory.Sessions
 			l = slices.Delete(l, i, i+1)
 		}
 	}
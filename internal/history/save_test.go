@@ -1,7 +1,12 @@
 package history
 
 import (
+	"github.com/byawitz/ggh/internal/command"
 	"github.com/byawitz/ggh/internal/config"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 )
@@ -26,3 +31,133 @@ func TestMarshal(t *testing.T) {
 		//t.Errorf("marshal json fail. Got %v, want %v", jsonString, converted)
 	}
 }
+
+func TestConnection(t *testing.T) {
+	a, err := command.ParseSSH([]string{"-l", "deploy", "-o", "HostName=10.0.0.5", "-A", "-i", "~/.ssh/a", "-J", "bastion", "-L", "8080:db:5432", "web", "-p", "2222", "uptime"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := connection(a, config.SSHConfig{Host: a.Destination})
+	if c.Host != "10.0.0.5" || c.User != "deploy" || c.Port != "2222" {
+		t.Errorf("connection() = %s@%s:%s, want deploy@10.0.0.5:2222", c.User, c.Host, c.Port)
+	}
+
+	if c.Key != "~/.ssh/a" || c.ProxyJump != "bastion" || c.ForwardAgent != "yes" {
+		t.Errorf("connection() = key %q, jump %q, agent %q", c.Key, c.ProxyJump, c.ForwardAgent)
+	}
+
+	if len(c.LocalForward) != 1 || c.LocalForward[0] != "8080 db:5432" {
+		t.Errorf("connection() local forwards = %q, want [8080 db:5432]", c.LocalForward)
+	}
+}
+
+func TestAddHistoryConfigFile(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	file := filepath.Join(t.TempDir(), "work")
+	if err := os.WriteFile(file, []byte("Host web\n\tHostName web.example.com\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	config.SetConfigPath(file)
+	t.Cleanup(func() { config.SetConfigPath("") })
+
+	c := AddHistoryFromArgs([]string{"-F", file, "web"})
+	if c.Name != "web" || c.SourceFile != file {
+		t.Errorf("AddHistoryFromArgs() = %+v, want the host web of %s", c, file)
+	}
+
+	list, err := Fetch(getFile())
+	if err != nil || len(list) != 1 {
+		t.Fatalf("Fetch() = %+v, %v, want one entry", list, err)
+	}
+
+	if want := []string{"-F", file, "web"}; !slices.Equal(list[0].Args, want) {
+		t.Errorf("stored args = %q, want %q", list[0].Args, want)
+	}
+}
+
+func TestStringifySkipsAnnotations(t *testing.T) {
+	entry := SSHHistory{Connection: config.SSHConfig{
+		Name:        "db1",
+		Host:        "10.0.0.5",
+		Tags:        []string{"prod"},
+		Description: "primary postgres",
+		Labels:      map[string]string{"env": "eu"},
+	}}
+
+	jsonString := stringify(entry, nil)
+	for _, field := range []string{"tags", "description", "labels", "prod", "eu"} {
+		if strings.Contains(jsonString, field) {
+			t.Errorf("stringify() saved the annotation %q: %s", field, jsonString)
+		}
+	}
+}
+
+func TestAddHistoryAliasWithFlags(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	file := filepath.Join(t.TempDir(), "config")
+	content := "Host db\n\tHostName db.example.com\n\tUser postgres\n\tProxyJump bastion\n\tIdentityFile ~/.ssh/db\n"
+	if err := os.WriteFile(file, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	config.SetConfigPath(file)
+	t.Cleanup(func() { config.SetConfigPath("") })
+
+	c := AddHistoryFromArgs([]string{"db", "-A", "-p", "2222", "-i", "~/.ssh/other"})
+	if c.Name != "db" || c.Host != "db.example.com" || c.User != "postgres" || c.ProxyJump != "bastion" {
+		t.Errorf("AddHistoryFromArgs() = %+v, want the host db of the config", c)
+	}
+
+	if c.Port != "2222" || c.ForwardAgent != "yes" {
+		t.Errorf("AddHistoryFromArgs() = port %q, agent %q, want 2222 and yes", c.Port, c.ForwardAgent)
+	}
+
+	if want := []string{"~/.ssh/other", filepath.Join(config.HomeDir(), ".ssh/db")}; c.Key != want[0] || !slices.Equal(c.IdentityFiles, want) {
+		t.Errorf("AddHistoryFromArgs() = key %q, identity files %q, want %q", c.Key, c.IdentityFiles, want)
+	}
+
+	list, err := Fetch(getFile())
+	if err != nil || len(list) != 1 || list[0].Connection.Name != "db" {
+		t.Fatalf("Fetch() = %+v, %v, want the entry of db", list, err)
+	}
+}
+
+func TestAddHistoryRecordsAbsoluteConfig(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "work")
+	if err := os.WriteFile(file, []byte("Host web\n\tHostName web.example.com\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(dir)
+
+	config.SetConfigPath("work")
+	AddHistoryFromArgs([]string{"-F", "work", "web"})
+	config.SetConfigPath("")
+
+	list, err := Fetch(getFile())
+	if err != nil || len(list) != 1 {
+		t.Fatalf("Fetch() = %+v, %v, want one entry", list, err)
+	}
+
+	if want := []string{"-F", file, "web"}; !slices.Equal(list[0].Args, want) {
+		t.Errorf("stored args = %q, want %q", list[0].Args, want)
+	}
+
+	t.Setenv("GGH_SSH_CONFIG", file)
+	AddHistoryFromArgs([]string{"-A", "web"})
+
+	list, err = Fetch(getFile())
+	if err != nil || len(list) != 1 {
+		t.Fatalf("Fetch() = %+v, %v, want one entry", list, err)
+	}
+
+	if want := []string{"-F", file, "-A", "web"}; !slices.Equal(list[0].Args, want) {
+		t.Errorf("stored args = %q, want %q", list[0].Args, want)
+	}
+}
@@ -0,0 +1,70 @@
+package interactive
+
+import (
+	"strings"
+
+	"github.com/byawitz/ggh/internal/command"
+	"github.com/byawitz/ggh/internal/ssh"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// command returns the ssh arguments to connect to the selected entry: the ones
+// it was last connected with, or else the ones its config calls for.
+func (m model) command() (ssh.Args, bool) {
+	entry, ok := m.selectedEntry()
+	if !ok {
+		return ssh.Args{}, false
+	}
+
+	if entry < len(m.args) && len(m.args[entry]) > 0 {
+		if a, err := command.ParseSSH(m.args[entry]); err == nil {
+			return ssh.Args{Options: a.Options(), Destination: a.Target, Command: a.Command}, true
+		}
+	}
+
+	return ssh.GenerateCommandArgs(m.entries[entry]), true
+}
+
+// startArgs lets the ssh arguments of the selected entry be edited before
+// connecting with them.
+func (m model) startArgs() model {
+	args, ok := m.command()
+	if !ok {
+		return m
+	}
+
+	m.mode = editingArgs
+	m.input = newInput("ssh ", strings.TrimPrefix(args.String(), "ssh "))
+	m.status = ""
+
+	return m
+}
+
+func (m model) updateArgs(msg tea.KeyMsg) (model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "ctrl+c":
+		m.mode = browsing
+		m.status = ""
+		return m, nil
+	case "enter":
+	default:
+		m.input.Update(msg)
+		return m, nil
+	}
+
+	args, err := ssh.Split(m.input.Value())
+	if err != nil {
+		m.status = err.Error()
+		return m, nil
+	}
+
+	if len(args) == 0 {
+		m.status = "no arguments to connect with"
+		return m, nil
+	}
+
+	m.mode = browsing
+	m.choice = args
+
+	return m, tea.Quit
+}
@@ -0,0 +1,123 @@
+package interactive
+
+import (
+	"cmp"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/byawitz/ggh/internal/config"
+	"github.com/byawitz/ggh/internal/history"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// detailSessions is how many of the last sessions the details show.
+const detailSessions = 5
+
+// DetailsView shows everything known about the selected host, untruncated:
+// where it is defined, every option, the route to it and its last sessions.
+func (m model) DetailsView() string {
+	c, ok := m.selected()
+	if !ok {
+		return ""
+	}
+
+	destination := net.JoinHostPort(c.Host, cmp.Or(c.Port, "22"))
+	if c.User != "" {
+		destination = c.User + "@" + destination
+	}
+
+	source := "not defined in ssh config"
+	if c.SourceFile != "" {
+		source = c.Source()
+	}
+
+	if c.Origin == config.OriginSystem {
+		source += " (system)"
+	}
+
+	lines := []string{
+		generateHelpBlock("host", destination, false),
+		generateHelpBlock("source", source, false),
+	}
+
+	if args, ok := m.command(); ok {
+		lines = append(lines, generateHelpBlock("command", args.String(), false))
+	}
+
+	if c.Description != "" {
+		lines = append(lines, generateHelpBlock("description", c.Description, false))
+	}
+
+	if annotations := c.Annotations(); annotations != "" {
+		lines = append(lines, generateHelpBlock("tags", annotations, false))
+	}
+
+	if len(c.IdentityFiles) > 0 {
+		files := make([]string, len(c.IdentityFiles))
+		for i, file := range c.IdentityFiles {
+			files[i] = config.ShortPath(file)
+		}
+		lines = append(lines, generateHelpBlock("identity", strings.Join(files, ", "), false))
+	}
+
+	if hops := config.JumpChain(c, m.hosts); len(hops) > 0 {
+		lines = append(lines, generateHelpBlock("route", config.ChainString(c, m.hosts), false))
+
+		for _, hop := range hops {
+			detail := hop.String()
+			if !hop.Defined {
+				detail += " (not defined in ssh config)"
+			}
+			lines = append(lines, generateHelpBlock("  "+hop.Name, detail, false))
+		}
+	} else if c.ProxyCommand != "" {
+		lines = append(lines, generateHelpBlock("proxy command", c.ProxyCommand, false))
+	}
+
+	if len(c.Options) > 0 {
+		lines = append(lines, generateHelpBlock("options", "", false))
+		lines = append(lines, m.optionsView(c.Options)...)
+	}
+
+	if sessions := history.Sessions(m.recent, c, detailSessions); len(sessions) > 0 {
+		lines = append(lines, generateHelpBlock("sessions", "", false))
+
+		now := time.Now()
+		for _, session := range sessions {
+			detail := fmt.Sprintf("%s, exit %d", history.ReadableDuration(session.Duration), session.ExitCode)
+			lines = append(lines, generateHelpBlock("  "+history.ReadableTime(now.Sub(session.Date)), detail, false))
+		}
+	}
+
+	return strings.Join(lines, "\n  ")
+}
+
+// optionsView lays out the options of a host sorted by keyword, as many on a
+// line as fit in the window.
+func (m model) optionsView(options map[string][]string) []string {
+	keywords := make([]string, 0, len(options))
+	for keyword := range options {
+		keywords = append(keywords, keyword)
+	}
+	slices.Sort(keywords)
+
+	width := max(m.windowWidth-MarginWidth-2, 20)
+
+	var lines []string
+	line := " "
+	for _, keyword := range keywords {
+		for _, value := range options[keyword] {
+			block := " " + generateHelpBlock(keyword, value, false) + " "
+			if lipgloss.Width(line+block) > width && line != " " {
+				lines = append(lines, line)
+				line = " "
+			}
+			line += block
+		}
+	}
+
+	return append(lines, line)
+}
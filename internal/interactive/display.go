@@ -4,58 +4,186 @@ import (
 	"fmt"
 	"github.com/byawitz/ggh/internal/config"
 	"github.com/byawitz/ggh/internal/history"
-	"github.com/byawitz/ggh/internal/ssh"
+	"github.com/byawitz/ggh/internal/settings"
 	"github.com/charmbracelet/bubbles/table"
 	"log"
 	"os"
+	"slices"
 	"time"
 )
 
+// listing is what a picker shows: the entries with their table rows and, for
+// a search, the matched characters of each cell. Boosts, when set, are added
+// to the score of each entry when the picker filters them. Args, when set,
+// are the ssh arguments to connect to each entry with.
+type listing struct {
+	rows       []table.Row
+	entries    []config.SSHConfig
+	highlights [][][]int
+	boosts     []int
+	args       [][]string
+}
+
+// loader builds the listing of a picker, so the picker can reload it after
+// the underlying files changed.
+type loader func() (listing, error)
+
+// highlightColumns maps the fields a search is matched against to the
+// columns of both pickers.
+var highlightColumns = map[string]int{
+	config.FieldName: 0,
+	config.FieldHost: 1,
+	config.FieldUser: 3,
+	config.FieldTags: 7,
+}
+
+func highlights(m config.Match, columns int) [][]int {
+	h := make([][]int, columns)
+	for field, positions := range m.Positions {
+		h[highlightColumns[field]] = positions
+	}
+
+	return h
+}
+
+// Config opens the picker on the hosts of the ssh config matching value.
 func Config(value string) []string {
-	list, err := config.ParseWithSearch(value, config.GetConfigFile())
-	if err != nil || len(list) == 0 {
+	l, err := configRows(value)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if len(l.entries) == 0 {
 		fmt.Println("No config found.")
 		os.Exit(0)
 	}
 
-	var rows []table.Row
+	return Select(l, tabs(value), SelectConfig)
+}
+
+// configRows lists the hosts matching the query value, with when they were
+// last connected to. With a query they are ranked by how well they match,
+// boosted by how recently and how often they were connected to.
+func configRows(value string) (listing, error) {
+	hosts, err := config.Load()
+	if err != nil {
+		return listing{}, err
+	}
+
+	s := settings.FetchWithDefaultFile()
+	list := hosts
+	if s.HideSystemHosts {
+		list = config.WithoutSystem(list)
+	}
+
+	q := config.ParseQuery(value)
+	scores := map[string]int{}
+	boosts := map[string]int{}
+	var matched []config.SSHConfig
+
+	recent, _ := history.FetchWithDefaultFile()
+	now := time.Now()
+
 	for _, c := range list {
-		rows = append(rows, table.Row{
+		m, ok := q.Match(c)
+		if !ok {
+			continue
+		}
+
+		boosts[c.Name] = history.Boost(recent, c, now)
+		scores[c.Name] = m.Score + boosts[c.Name]
+		matched = append(matched, c)
+	}
+
+	if len(q) > 0 {
+		slices.SortStableFunc(matched, func(a, b config.SSHConfig) int {
+			return scores[b.Name] - scores[a.Name]
+		})
+	}
+
+	if s.ResolveWithSSH {
+		matched = config.ResolveAll(matched)
+	}
+
+	l := listing{entries: matched}
+	for _, c := range matched {
+		lastUsed := ""
+		if last, ok := history.LastUsed(recent, c); ok {
+			lastUsed = history.ReadableTime(now.Sub(last))
+		}
+
+		l.rows = append(l.rows, table.Row{
 			c.Name,
 			c.Host,
 			c.Port,
 			c.User,
-			c.Key,
+			config.ShortPath(c.Key),
+			c.Jump(hosts),
+			c.Forwards(),
+			c.Annotations(),
+			lastUsed,
 		})
+
+		// Resolved values may differ from the parsed ones, so the match is
+		// taken again for the highlights.
+		m, _ := q.Match(c)
+		l.highlights = append(l.highlights, highlights(m, len(l.rows[0])))
+		l.boosts = append(l.boosts, boosts[c.Name])
 	}
-	c := Select(rows, SelectConfig)
-	return ssh.GenerateCommandArgs(c)
+
+	return l, nil
 }
 
+// History opens the picker on the recent connections, or on the hosts of the
+// ssh config when there are none yet.
 func History() []string {
-	list, err := history.FetchWithDefaultFile()
+	what := SelectHistory
+	l, err := historyRows()
 
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	if len(list) == 0 {
+	if len(l.entries) == 0 {
+		what = SelectConfig
+		if l, err = configRows(""); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if len(l.entries) == 0 {
 		fmt.Println("No history found.")
 		os.Exit(0)
 	}
 
-	var rows []table.Row
+	return Select(l, tabs(""), what)
+}
+
+func historyRows() (listing, error) {
+	list, err := history.FetchWithDefaultFile()
+	if err != nil {
+		return listing{}, err
+	}
+
+	hosts, _ := config.Load()
+
+	var l listing
 	currentTime := time.Now()
 	for _, historyItem := range list {
-		rows = append(rows, table.Row{
+		l.entries = append(l.entries, historyItem.Connection)
+		l.args = append(l.args, historyItem.Args)
+		l.rows = append(l.rows, table.Row{
 			historyItem.Connection.Name,
 			historyItem.Connection.Host,
 			historyItem.Connection.Port,
 			historyItem.Connection.User,
-			historyItem.Connection.Key,
+			config.ShortPath(historyItem.Connection.Key),
+			historyItem.Connection.Jump(hosts),
+			historyItem.Connection.Forwards(),
+			historyItem.Connection.Annotations(),
 			fmt.Sprintf("%s", history.ReadableTime(currentTime.Sub(historyItem.Date))),
 		})
 	}
-	c := Select(rows, SelectHistory)
-	return ssh.GenerateCommandArgs(c)
+
+	return l, nil
 }
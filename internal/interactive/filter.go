@@ -0,0 +1,138 @@
+package interactive
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/byawitz/ggh/internal/config"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// startFilter focuses the filter bar, keeping what was typed before. It is
+// only ever opened with /, so that the letters bound to actions, such as d
+// deleting a history entry, never act while a search is being typed.
+func (m model) startFilter() model {
+	m.mode = filtering
+	m.status = ""
+
+	return m
+}
+
+// updateFilter edits the filter while it is focused. The keys moving the
+// cursor still move it, enter picks the selected host and esc clears the
+// filter.
+func (m model) updateFilter(msg tea.KeyMsg) (model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		m.exit = true
+		return m, tea.Quit
+	case "esc":
+		m.mode = browsing
+		m.clearFilter()
+		return m, nil
+	case "enter":
+		m.mode = browsing
+		if m.toggleGroup() {
+			return m, nil
+		}
+
+		if args, ok := m.command(); ok {
+			m.choice = args.Argv()
+			return m, tea.Quit
+		}
+		return m, nil
+	case "tab":
+		m.switchTab(m.active + 1)
+		return m, nil
+	case "shift+tab":
+		m.switchTab(m.active - 1)
+		return m, nil
+	case "up", "down", "pgup", "pgdown", "ctrl+p", "ctrl+n":
+		switch msg.String() {
+		case "ctrl+p":
+			msg = tea.KeyMsg{Type: tea.KeyUp}
+		case "ctrl+n":
+			msg = tea.KeyMsg{Type: tea.KeyDown}
+		}
+		m.table, _ = m.table.Update(msg)
+		return m, nil
+	}
+
+	before := m.filter.Value()
+	if !m.filter.Update(msg) || m.filter.Value() == before {
+		return m, nil
+	}
+
+	m.refresh()
+	if m.filter.Value() != "" {
+		// The best match is on top, which is where the cursor goes while
+		// typing.
+		m.selectFirst()
+	}
+
+	return m, nil
+}
+
+// clearFilter empties the filter, keeping the cursor on the selected host.
+func (m *model) clearFilter() {
+	if m.filter.Value() == "" {
+		return
+	}
+
+	m.filter.SetValue("")
+	m.refresh()
+}
+
+// filtered returns the entries matching the filter, best first, and the
+// highlights of every entry. Without a filter all entries are returned in
+// their order, highlighted as the listing was.
+func (m model) filtered() ([]int, [][][]int) {
+	q := config.ParseQuery(m.filter.Value())
+
+	visible := make([]int, 0, len(m.entries))
+	if len(q) == 0 {
+		for i := range m.entries {
+			visible = append(visible, i)
+		}
+		return visible, m.highlights
+	}
+
+	scores := make([]int, len(m.entries))
+	hl := make([][][]int, len(m.entries))
+	for i, c := range m.entries {
+		match, ok := q.Match(c)
+		if !ok {
+			continue
+		}
+
+		scores[i] = match.Score
+		if i < len(m.boosts) {
+			scores[i] += m.boosts[i]
+		}
+		hl[i] = highlights(match, len(m.rows[i]))
+		visible = append(visible, i)
+	}
+
+	slices.SortStableFunc(visible, func(a, b int) int {
+		return scores[b] - scores[a]
+	})
+
+	return visible, hl
+}
+
+// selectFirst moves the cursor to the first host, past any group header.
+func (m *model) selectFirst() {
+	if i := slices.IndexFunc(m.lines, func(l line) bool { return !l.header() }); i != -1 {
+		m.table.SetCursor(i)
+	}
+}
+
+func (m model) FilterView() string {
+	count := fmt.Sprintf("  %d/%d", len(m.visible), len(m.entries))
+
+	if m.mode == filtering {
+		return m.filter.View() + generateHelpBlock(count, "", false)
+	}
+
+	return generateHelpBlock("filter", m.filter.Value(), false) + generateHelpBlock(count, "", false)
+}
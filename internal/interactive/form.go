@@ -0,0 +1,278 @@
+package interactive
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/byawitz/ggh/internal/config"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	fieldAlias = iota
+	fieldHostName
+	fieldUser
+	fieldPort
+	fieldIdentityFile
+	fieldProxyJump
+	fieldOptions
+)
+
+// hostForm collects the options of a new Host block.
+type hostForm struct {
+	fields      []input
+	focus       int
+	err         string
+	completions []string
+}
+
+func newHostForm() hostForm {
+	return hostForm{
+		fields: []input{
+			newInput("Alias         ", ""),
+			newInput("HostName      ", ""),
+			newInput("User          ", ""),
+			newInput("Port          ", ""),
+			newInput("IdentityFile  ", ""),
+			newInput("ProxyJump     ", ""),
+			newInput("Options       ", ""),
+		},
+	}
+}
+
+// Update handles a key press and reports whether the form was submitted.
+func (f hostForm) Update(msg tea.KeyMsg) (hostForm, bool) {
+	switch msg.String() {
+	case "tab":
+		if f.focus == fieldIdentityFile {
+			f.complete()
+			return f, false
+		}
+		f.focus = min(f.focus+1, len(f.fields)-1)
+	case "down":
+		f.focus = min(f.focus+1, len(f.fields)-1)
+	case "shift+tab", "up":
+		f.focus = max(f.focus-1, 0)
+	case "enter":
+		if f.focus < len(f.fields)-1 {
+			f.focus++
+			return f, false
+		}
+		return f, true
+	case "ctrl+s":
+		return f, true
+	default:
+		f.fields[f.focus].Update(msg)
+	}
+
+	f.completions = nil
+
+	return f, false
+}
+
+func (f hostForm) value(field int) string {
+	return strings.TrimSpace(f.fields[field].Value())
+}
+
+// complete extends the IdentityFile field with the files of the ssh directory
+// that start with what was typed so far.
+func (f *hostForm) complete() {
+	typed := f.value(fieldIdentityFile)
+	prefix := strings.TrimPrefix(strings.TrimPrefix(typed, "~/.ssh/"), config.GetSshDir()+string(filepath.Separator))
+
+	entries, err := os.ReadDir(config.GetSshDir())
+	if err != nil {
+		return
+	}
+
+	var matches []string
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasPrefix(name, prefix) || !looksLikeKey(name) {
+			continue
+		}
+		matches = append(matches, name)
+	}
+
+	if len(matches) == 0 {
+		f.completions = nil
+		return
+	}
+
+	common := matches[0]
+	for _, match := range matches[1:] {
+		for !strings.HasPrefix(match, common) {
+			common = common[:len(common)-1]
+		}
+	}
+
+	f.fields[fieldIdentityFile].SetValue("~/.ssh/" + common)
+	f.completions = nil
+	if len(matches) > 1 {
+		f.completions = matches
+	}
+}
+
+func looksLikeKey(name string) bool {
+	if strings.HasSuffix(name, ".pub") || strings.Contains(name, ".bak-") {
+		return false
+	}
+
+	for _, other := range []string{"config", "known_hosts", "authorized_keys", "environment"} {
+		if strings.HasPrefix(name, other) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// options validates the form and returns the alias with its Host block options.
+func (f hostForm) options() (string, []config.HostOption, error) {
+	alias := f.value(fieldAlias)
+	if err := validateAlias(alias); err != nil {
+		return "", nil, err
+	}
+
+	var opts []config.HostOption
+	add := func(keyword string, value string) {
+		if value != "" {
+			opts = append(opts, config.HostOption{Keyword: keyword, Args: []string{value}})
+		}
+	}
+
+	add("HostName", f.value(fieldHostName))
+	add("User", f.value(fieldUser))
+
+	if port := f.value(fieldPort); port != "" {
+		if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+			return "", nil, fmt.Errorf("port %q must be a number between 1 and 65535", port)
+		}
+		add("Port", port)
+	}
+
+	if key := f.value(fieldIdentityFile); key != "" {
+		if !filepath.IsAbs(key) && !strings.HasPrefix(key, "~") {
+			key = "~/.ssh/" + key
+		}
+
+		if _, err := os.Stat(config.ExpandPath(key)); err != nil {
+			return "", nil, fmt.Errorf("identity file %s does not exist", key)
+		}
+		add("IdentityFile", key)
+	}
+
+	add("ProxyJump", f.value(fieldProxyJump))
+
+	extra, err := config.ParseOptions(f.value(fieldOptions))
+	if err != nil {
+		return "", nil, err
+	}
+
+	for _, o := range extra {
+		if hasOption(opts, o.Keyword) {
+			return "", nil, errors.New(o.Keyword + " is already set above")
+		}
+	}
+
+	opts = append(opts, extra...)
+
+	// ssh uses whichever of the two comes first and ignores the other.
+	if hasOption(opts, "ProxyJump") && hasOption(opts, "ProxyCommand") {
+		return "", nil, errors.New("ProxyJump and ProxyCommand cannot be used together")
+	}
+
+	return alias, opts, nil
+}
+
+func hasOption(opts []config.HostOption, keyword string) bool {
+	return slices.ContainsFunc(opts, func(o config.HostOption) bool { return strings.EqualFold(o.Keyword, keyword) })
+}
+
+func (f hostForm) View() string {
+	labelStyle := lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#909090", Dark: "#626262"})
+	errorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
+
+	var b strings.Builder
+	b.WriteString(lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("212")).Render("New host") + "\n\n")
+
+	for i, field := range f.fields {
+		if i == f.focus {
+			b.WriteString(field.View())
+		} else {
+			b.WriteString(labelStyle.Render(field.prompt) + field.Value())
+		}
+		b.WriteString("\n")
+	}
+
+	if len(f.completions) > 0 {
+		b.WriteString("\n" + labelStyle.Render(strings.Join(f.completions, "  ")) + "\n")
+	}
+
+	if f.focus == fieldOptions {
+		b.WriteString("\n" + labelStyle.Render("e.g. ForwardAgent yes; ServerAliveInterval 30") + "\n")
+	}
+
+	if f.err != "" {
+		b.WriteString("\n" + errorStyle.Render(f.err) + "\n")
+	}
+
+	return b.String()
+}
+
+func (m model) startHostForm() model {
+	m.mode = addingHost
+	m.form = newHostForm()
+	m.status = ""
+
+	return m
+}
+
+func (m model) updateHostForm(msg tea.KeyMsg) (model, tea.Cmd) {
+	if msg.String() == "esc" || msg.String() == "ctrl+c" {
+		m.mode = browsing
+		return m, nil
+	}
+
+	form, submitted := m.form.Update(msg)
+	m.form = form
+	if !submitted {
+		return m, nil
+	}
+
+	alias, opts, err := m.form.options()
+	if err == nil {
+		err = writeHost(config.GetConfigPath(), alias, opts)
+	}
+
+	if err != nil {
+		m.form.err = err.Error()
+		return m, nil
+	}
+
+	m.mode = browsing
+	m.status = fmt.Sprintf("added %s to %s", alias, config.ShortPath(config.GetConfigPath()))
+	m.reload()
+	m.selectName(alias)
+
+	return m, nil
+}
+
+func writeHost(file string, alias string, opts []config.HostOption) error {
+	d, err := config.LoadDocument(file)
+	if err != nil {
+		return err
+	}
+
+	if err := d.AddHost(alias, opts); err != nil {
+		return err
+	}
+
+	return d.Save()
+}
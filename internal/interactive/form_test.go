@@ -0,0 +1,80 @@
+package interactive
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/byawitz/ggh/internal/config"
+)
+
+func filledForm(values map[int]string) hostForm {
+	f := newHostForm()
+	for field, value := range values {
+		f.fields[field].SetValue(value)
+	}
+
+	return f
+}
+
+func TestHostFormOptions(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	if err := os.MkdirAll(config.GetSshDir(), 0700); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range map[string]string{"config": "Host db1\n\tHostName 10.0.0.2\n", "id_web": "key"} {
+		if err := os.WriteFile(filepath.Join(config.GetSshDir(), name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	f := filledForm(map[int]string{
+		fieldAlias:        "web1",
+		fieldHostName:     "10.0.0.1",
+		fieldUser:         "deploy",
+		fieldPort:         "2222",
+		fieldIdentityFile: "id_web",
+		fieldProxyJump:    "bastion",
+		fieldOptions:      "ForwardAgent yes; LocalForward 8080 localhost:80",
+	})
+
+	alias, opts, err := f.options()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var lines []string
+	for _, o := range opts {
+		lines = append(lines, o.Keyword+" "+strings.Join(o.Args, " "))
+	}
+
+	want := []string{"HostName 10.0.0.1", "User deploy", "Port 2222", "IdentityFile ~/.ssh/id_web", "ProxyJump bastion", "ForwardAgent yes", "LocalForward 8080 localhost:80"}
+	if alias != "web1" || !slices.Equal(lines, want) {
+		t.Errorf("options() = %s, %q, want web1, %q", alias, lines, want)
+	}
+
+	tests := []struct {
+		name   string
+		values map[int]string
+		err    string
+	}{
+		{"invalid alias", map[int]string{fieldAlias: "web*"}, "not a valid alias"},
+		{"duplicate alias", map[int]string{fieldAlias: "db1"}, "already defined"},
+		{"port not a number", map[int]string{fieldAlias: "web2", fieldPort: "ssh"}, "between 1 and 65535"},
+		{"port out of range", map[int]string{fieldAlias: "web2", fieldPort: "65536"}, "between 1 and 65535"},
+		{"missing key", map[int]string{fieldAlias: "web2", fieldIdentityFile: "id_missing"}, "does not exist"},
+		{"invalid options", map[int]string{fieldAlias: "web2", fieldOptions: "ForwardAgent"}, ""},
+		{"option set twice", map[int]string{fieldAlias: "web2", fieldUser: "deploy", fieldOptions: "user root"}, "already set above"},
+		{"jump and proxy command", map[int]string{fieldAlias: "web2", fieldProxyJump: "bastion", fieldOptions: "ProxyCommand nc %h %p"}, "cannot be used together"},
+	}
+
+	for _, tt := range tests {
+		_, _, err := filledForm(tt.values).options()
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("%s: options() = %v, want an error containing %q", tt.name, err, tt.err)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package interactive
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+
+	"github.com/byawitz/ggh/internal/config"
+	"github.com/charmbracelet/bubbles/table"
+)
+
+const untagged = "untagged"
+
+// line is one row of the picker: the entry at index entry, or the header of
+// a tag group when entry is -1. In the grouped view a host with several tags
+// is listed under each of them.
+type line struct {
+	entry int
+	group string
+	count int
+}
+
+func (l line) header() bool {
+	return l.entry == -1
+}
+
+// layout returns the lines of the picker for the visible entries, in their
+// order. Grouped, the entries are listed under a header per tag, in
+// alphabetical order with untagged hosts last, and the entries of collapsed
+// groups are left out.
+func layout(entries []config.SSHConfig, visible []int, grouped bool, collapsed map[string]bool) []line {
+	lines := make([]line, 0, len(visible))
+
+	if !grouped {
+		for _, i := range visible {
+			lines = append(lines, line{entry: i})
+		}
+		return lines
+	}
+
+	groups := map[string][]int{}
+	for _, i := range visible {
+		c := entries[i]
+		if len(c.Tags) == 0 {
+			groups[untagged] = append(groups[untagged], i)
+		}
+
+		for _, tag := range c.Tags {
+			groups[tag] = append(groups[tag], i)
+		}
+	}
+
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		names = append(names, name)
+	}
+
+	slices.SortFunc(names, func(a, b string) int {
+		if (a == untagged) != (b == untagged) {
+			if a == untagged {
+				return 1
+			}
+			return -1
+		}
+		return cmp.Compare(a, b)
+	})
+
+	for _, name := range names {
+		lines = append(lines, line{entry: -1, group: name, count: len(groups[name])})
+		if collapsed[name] {
+			continue
+		}
+
+		for _, i := range groups[name] {
+			lines = append(lines, line{entry: i, group: name})
+		}
+	}
+
+	return lines
+}
+
+// refresh rebuilds the table from the entries matching the filter, keeping the
+// cursor on the same line when it is still shown.
+func (m *model) refresh() {
+	var current line
+	if cursor := m.table.Cursor(); cursor >= 0 && cursor < len(m.lines) {
+		current = m.lines[cursor]
+	}
+
+	var hl [][][]int
+	m.visible, hl = m.filtered()
+	m.lines = layout(m.entries, m.visible, m.grouped, m.collapsed)
+
+	columns := len(m.table.Columns())
+	rows := make([]table.Row, len(m.lines))
+	highlights := make([][][]int, len(m.lines))
+	for i, l := range m.lines {
+		if !l.header() {
+			rows[i] = m.rows[l.entry]
+			if l.entry < len(hl) {
+				highlights[i] = hl[l.entry]
+			}
+			continue
+		}
+
+		arrow := "▾"
+		if m.collapsed[l.group] {
+			arrow = "▸"
+		}
+
+		rows[i] = make(table.Row, columns)
+		rows[i][0] = fmt.Sprintf("%s %s (%d)", arrow, l.group, l.count)
+	}
+
+	m.table.SetRows(rows, highlights)
+	m.table.SetCursor(0)
+
+	// Prefer the same line, then the same entry under another group, then
+	// the header of the group the entry was collapsed into.
+	for _, same := range []func(l line) bool{
+		func(l line) bool { return l.entry == current.entry && l.group == current.group },
+		func(l line) bool { return !current.header() && l.entry == current.entry },
+		func(l line) bool { return l.header() && l.group == current.group },
+	} {
+		if i := slices.IndexFunc(m.lines, same); i != -1 {
+			m.table.SetCursor(i)
+			break
+		}
+	}
+
+	if !m.settings.Fullscreen {
+		m.table.SetHeight(m.windowedHeight())
+	}
+}
+
+// toggleGroup collapses or expands the group whose header is selected and
+// reports whether there was one.
+func (m *model) toggleGroup() bool {
+	cursor := m.table.Cursor()
+	if cursor < 0 || cursor >= len(m.lines) || !m.lines[cursor].header() {
+		return false
+	}
+
+	if m.collapsed == nil {
+		m.collapsed = map[string]bool{}
+	}
+
+	group := m.lines[cursor].group
+	m.collapsed[group] = !m.collapsed[group]
+	m.refresh()
+
+	return true
+}
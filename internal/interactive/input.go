@@ -0,0 +1,79 @@
+package interactive
+
+import (
+	"slices"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// input is a single line text field.
+type input struct {
+	prompt string
+	value  []rune
+	cursor int
+}
+
+func newInput(prompt string, value string) input {
+	return input{prompt: prompt, value: []rune(value), cursor: len([]rune(value))}
+}
+
+func (i input) Value() string {
+	return string(i.value)
+}
+
+func (i *input) SetValue(value string) {
+	i.value = []rune(value)
+	i.cursor = len(i.value)
+}
+
+// Update applies an editing key and reports whether the key was consumed.
+func (i *input) Update(msg tea.KeyMsg) bool {
+	switch msg.Type {
+	case tea.KeyRunes, tea.KeySpace:
+		runes := msg.Runes
+		if msg.Type == tea.KeySpace {
+			runes = []rune{' '}
+		}
+		i.value = slices.Insert(i.value, i.cursor, runes...)
+		i.cursor += len(runes)
+	case tea.KeyBackspace:
+		if i.cursor > 0 {
+			i.value = slices.Delete(i.value, i.cursor-1, i.cursor)
+			i.cursor--
+		}
+	case tea.KeyDelete:
+		if i.cursor < len(i.value) {
+			i.value = slices.Delete(i.value, i.cursor, i.cursor+1)
+		}
+	case tea.KeyLeft:
+		i.cursor = max(i.cursor-1, 0)
+	case tea.KeyRight:
+		i.cursor = min(i.cursor+1, len(i.value))
+	case tea.KeyHome, tea.KeyCtrlA:
+		i.cursor = 0
+	case tea.KeyEnd, tea.KeyCtrlE:
+		i.cursor = len(i.value)
+	case tea.KeyCtrlU:
+		i.value = i.value[i.cursor:]
+		i.cursor = 0
+	default:
+		return false
+	}
+
+	return true
+}
+
+func (i input) View() string {
+	promptStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("212"))
+	cursorStyle := lipgloss.NewStyle().Reverse(true)
+
+	cursor := " "
+	after := ""
+	if i.cursor < len(i.value) {
+		cursor = string(i.value[i.cursor])
+		after = string(i.value[i.cursor+1:])
+	}
+
+	return promptStyle.Render(i.prompt) + string(i.value[:i.cursor]) + cursorStyle.Render(cursor) + after
+}
@@ -0,0 +1,131 @@
+package interactive
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/byawitz/ggh/internal/config"
+	"github.com/byawitz/ggh/internal/history"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// startPromote asks for an alias for the selected history entry, which is then
+// written as a Host block and linked back to the history.
+func (m model) startPromote() model {
+	c, ok := m.selected()
+	if !ok {
+		return m
+	}
+
+	m.mode = promotingAlias
+	m.promote = c
+	m.input = newInput("Alias: ", "")
+	m.status = ""
+
+	return m
+}
+
+func (m model) updatePromote(msg tea.KeyMsg) (model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "ctrl+c":
+		m.mode = browsing
+		return m, nil
+	case "enter":
+	default:
+		m.input.Update(msg)
+		return m, nil
+	}
+
+	value := strings.TrimSpace(m.input.Value())
+
+	if m.mode == promotingAlias {
+		if err := validateAlias(value); err != nil {
+			m.status = err.Error()
+			return m, nil
+		}
+
+		m.promoteAlias = value
+		m.mode = promotingFile
+		m.input = newInput("Save to: ", config.ShortPath(config.GetConfigPath()))
+		m.status = ""
+		return m, nil
+	}
+
+	file := config.ExpandPath(value)
+	if err := writeHost(file, m.promoteAlias, hostOptions(m.promote)); err != nil {
+		m.status = err.Error()
+		return m, nil
+	}
+
+	m.mode = browsing
+	m.status = fmt.Sprintf("saved %s to %s", m.promoteAlias, config.ShortPath(file))
+	if c, err := config.GetConfig(m.promoteAlias); err == nil && c.Name == "" {
+		m.status += ", but that file is not included from " + config.ShortPath(config.GetConfigPath())
+	}
+
+	if err := history.Relink(m.promote, m.promoteAlias); err != nil {
+		m.status = err.Error()
+	}
+
+	m.reload()
+
+	return m, nil
+}
+
+func validateAlias(alias string) error {
+	if alias == "" || strings.ContainsAny(alias, "*?!,# \t\"'") {
+		return fmt.Errorf("%q is not a valid alias", alias)
+	}
+
+	c, err := config.GetConfig(alias)
+	if err != nil {
+		return err
+	}
+
+	if c.Name != "" {
+		return fmt.Errorf("%s is already defined in %s", alias, c.Source())
+	}
+
+	return nil
+}
+
+// hostOptions turns a connection into Host block options, keeping the values
+// exactly as they were used. Forwards are already in the "listen target" form
+// of ssh_config, and values of several words are written unquoted, as ssh
+// reads a ProxyCommand up to the end of the line.
+func hostOptions(c config.SSHConfig) []config.HostOption {
+	opts := []config.HostOption{{Keyword: "HostName", Args: []string{c.Host}}}
+
+	add := func(keyword string, value string) {
+		if value != "" {
+			opts = append(opts, config.HostOption{Keyword: keyword, Args: strings.Fields(value)})
+		}
+	}
+
+	add("User", c.User)
+	add("Port", c.Port)
+
+	identityFiles := c.IdentityFiles
+	if len(identityFiles) == 0 && c.Key != "" {
+		identityFiles = []string{c.Key}
+	}
+	for _, f := range identityFiles {
+		opts = append(opts, config.HostOption{Keyword: "IdentityFile", Args: []string{f}})
+	}
+
+	add("ProxyJump", c.ProxyJump)
+	add("ProxyCommand", c.ProxyCommand)
+	add("ForwardAgent", c.ForwardAgent)
+
+	for _, f := range c.LocalForward {
+		add("LocalForward", f)
+	}
+	for _, f := range c.RemoteForward {
+		add("RemoteForward", f)
+	}
+	for _, f := range c.DynamicForward {
+		add("DynamicForward", f)
+	}
+
+	return opts
+}
@@ -0,0 +1,68 @@
+package interactive
+
+import (
+	"testing"
+
+	"github.com/byawitz/ggh/internal/config"
+)
+
+func TestHostOptions(t *testing.T) {
+	tests := []struct {
+		name       string
+		connection config.SSHConfig
+		block      string
+	}{
+		{
+			"host only",
+			config.SSHConfig{Host: "10.0.0.5"},
+			"Host web\n\tHostName 10.0.0.5\n",
+		},
+		{
+			"user, port and a key",
+			config.SSHConfig{Host: "10.0.0.5", User: "deploy", Port: "2222", Key: "~/.ssh/web"},
+			"Host web\n\tHostName 10.0.0.5\n\tUser deploy\n\tPort 2222\n\tIdentityFile ~/.ssh/web\n",
+		},
+		{
+			"several keys",
+			config.SSHConfig{Host: "10.0.0.5", Key: "/Users/Jane Doe/.ssh/a", IdentityFiles: []string{"/Users/Jane Doe/.ssh/a", "~/.ssh/b"}},
+			"Host web\n\tHostName 10.0.0.5\n\tIdentityFile \"/Users/Jane Doe/.ssh/a\"\n\tIdentityFile ~/.ssh/b\n",
+		},
+		{
+			"jump and agent",
+			config.SSHConfig{Host: "10.0.0.5", ProxyJump: "admin@bastion:2200,gw", ForwardAgent: "yes"},
+			"Host web\n\tHostName 10.0.0.5\n\tProxyJump admin@bastion:2200,gw\n\tForwardAgent yes\n",
+		},
+		{
+			"proxy command",
+			config.SSHConfig{Host: "10.0.0.5", ProxyCommand: "ssh -W %h:%p gw"},
+			"Host web\n\tHostName 10.0.0.5\n\tProxyCommand ssh -W %h:%p gw\n",
+		},
+		{
+			"forwards",
+			config.SSHConfig{
+				Host:           "10.0.0.5",
+				LocalForward:   []string{"8080 db:5432", "9090 localhost:90"},
+				RemoteForward:  []string{"[::1]:9000 localhost:9000"},
+				DynamicForward: []string{"1080"},
+			},
+			"Host web\n\tHostName 10.0.0.5\n\tLocalForward 8080 db:5432\n\tLocalForward 9090 localhost:90\n" +
+				"\tRemoteForward [::1]:9000 localhost:9000\n\tDynamicForward 1080\n",
+		},
+	}
+
+	for _, tt := range tests {
+		d, err := config.ParseDocument("config", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := d.AddHost("web", hostOptions(tt.connection)); err != nil {
+			t.Errorf("%s: AddHost failed: %v", tt.name, err)
+			continue
+		}
+
+		if d.String() != tt.block {
+			t.Errorf("%s: hostOptions wrote:\n%s\nwant:\n%s", tt.name, d.String(), tt.block)
+		}
+	}
+}
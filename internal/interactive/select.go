@@ -3,6 +3,7 @@ package interactive
 import (
 	"fmt"
 	"github.com/byawitz/ggh/internal/config"
+	"github.com/byawitz/ggh/internal/editor"
 	"github.com/byawitz/ggh/internal/history"
 	"github.com/byawitz/ggh/internal/settings"
 	"github.com/byawitz/ggh/internal/theme"
@@ -21,25 +22,68 @@ type Selecting int
 const (
 	SelectConfig Selecting = iota
 	SelectHistory
+	SelectFavorites
 )
 
 const (
 	MarginWidth            = 3
-	MarginHeight           = 5
+	MarginHeight           = 6
 	MinimumTableWidth      = 3
-	ContentExtraMargin     = 12
+	ContentExtraMargin     = 2
 	PreferredKeyExtraWidth = 15
 	MaxKeyExtraWidth       = 30
 )
 
+type mode int
+
+const (
+	browsing mode = iota
+	promotingAlias
+	promotingFile
+	addingHost
+	filtering
+	editingArgs
+)
+
 type model struct {
-	table        table.Model
-	choice       config.SSHConfig
+	table        hostTable
+	entries      []config.SSHConfig
+	load         loader
+	tabs         []tab
+	active       int
+	choice       []string
 	what         Selecting
 	exit         bool
 	windowWidth  int
 	windowHeight int
 	settings     settings.Settings
+	showDetails  bool
+	hosts        []config.SSHConfig
+	recent       []history.SSHHistory
+	err          error
+	status       string
+	mode         mode
+	input        input
+	promote      config.SSHConfig
+	promoteAlias string
+	form         hostForm
+	// rows, highlights, boosts and args hold the table row of every entry,
+	// the characters the search matched, how much to boost it when ranking
+	// the filtered entries and the ssh arguments it was last connected with;
+	// visible are the entries matching the filter and lines is what is shown.
+	rows       []table.Row
+	highlights [][][]int
+	boosts     []int
+	args       [][]string
+	visible    []int
+	lines      []line
+	grouped    bool
+	collapsed  map[string]bool
+	filter     input
+}
+
+type editorFinishedMsg struct {
+	err error
 }
 
 func (m model) Init() tea.Cmd { return nil }
@@ -52,98 +96,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.windowWidth = msg.Width
 		m.windowHeight = msg.Height - MarginHeight
 
-		widthForTable := max(m.windowWidth-MarginWidth, MinimumTableWidth)
-		// Extra margin for content
-		widthForTableContent := widthForTable - ContentExtraMargin
-
-		cols := m.table.Columns()
-
-		switch m.what {
-		// SELECT CONFIG
-		case SelectConfig:
-			// columns = [Name, Host, Port, User, Key]
-			// base widths = 15,20,5,10,10 = total 60
-			baseWidths := []int{15, 20, 5, 10, 10}
-			const totalBase = 60
-
-			if widthForTableContent >= totalBase {
-				leftover := widthForTableContent - totalBase
-				leftoverForKey := 0
-				leftoverForName := 0
-
-				for leftover > 0 {
-					if leftoverForKey < PreferredKeyExtraWidth {
-						leftoverForKey++
-						leftover--
-					} else if leftoverForKey < MaxKeyExtraWidth && leftover > 1 {
-						leftoverForName++
-						leftoverForKey++
-						leftover -= 2
-					} else {
-						leftoverForName++
-						leftover--
-					}
-				}
-
-				cols[0].Width = baseWidths[0] + leftoverForName // Name
-				cols[1].Width = baseWidths[1]                   // Host
-				cols[2].Width = baseWidths[2]                   // Port
-				cols[3].Width = baseWidths[3]                   // User
-				cols[4].Width = baseWidths[4] + leftoverForKey  // Key
-			} else {
-				// Scale all columns proportionally
-				ratio := float64(widthForTableContent) / float64(totalBase)
-				for i := range cols {
-					w := max(int(math.Round(float64(baseWidths[i])*ratio)), 1)
-					cols[i].Width = w
-				}
-			}
-
-		// SELECT HISTORY
-		case SelectHistory:
-			// columns = [Name,Host,Port,User,Key,Last login]
-			// base widths = 10,20,5,10,0,15 = total 60
-			baseWidths := []int{10, 20, 5, 10, 0, 15}
-			const totalBase = 60
-
-			if widthForTableContent >= totalBase {
-				leftover := widthForTableContent - totalBase
-				leftoverForKey := 0
-				leftoverForName := 0
-
-				for leftover > 0 {
-					if leftoverForKey < PreferredKeyExtraWidth {
-						leftoverForKey++
-						leftover--
-					} else if leftoverForKey < MaxKeyExtraWidth && leftover > 1 {
-						leftoverForName++
-						leftoverForKey++
-						leftover -= 2
-					} else {
-						leftoverForName++
-						leftover--
-					}
-				}
-
-				cols[0].Width = baseWidths[0] + leftoverForName // Name
-				cols[1].Width = baseWidths[1]                   // Host
-				cols[2].Width = baseWidths[2]                   // Port
-				cols[3].Width = baseWidths[3]                   // User
-				cols[4].Width = baseWidths[4] + leftoverForKey  // Key
-				cols[5].Width = baseWidths[5]                   // Last login
-			} else {
-				// Not enough space → scale all columns proportionally
-				ratio := float64(widthForTableContent) / float64(totalBase)
-				for i := range cols {
-					w := max(int(math.Round(float64(baseWidths[i])*ratio)), 1)
-					cols[i].Width = w
-				}
-			}
-		}
-
-		// Apply the new widths
-		m.table.SetColumns(cols)
-		m.table.SetWidth(widthForTable)
+		m.resize()
 		m.settings = settings.FetchWithDefaultFile()
 		if m.settings.Fullscreen {
 			// if fullscreen, let the table be as tall as the terminal
@@ -151,20 +104,58 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, tea.EnterAltScreen
 		} else {
 			// if not fullscreen, set the height to a minimum of 8 rows
-			m.table.SetHeight(int(math.Min(8, float64(len(m.table.Rows())+1))))
+			m.table.SetHeight(m.windowedHeight())
 			return m, tea.ExitAltScreen
 		}
 
+	case editorFinishedMsg:
+		m.err = msg.err
+		if m.err == nil {
+			m.reload()
+		}
+		return m, nil
+
 	case tea.KeyMsg:
+		switch m.mode {
+		case promotingAlias, promotingFile:
+			return m.updatePromote(msg)
+		case addingHost:
+			return m.updateHostForm(msg)
+		case filtering:
+			return m.updateFilter(msg)
+		case editingArgs:
+			return m.updateArgs(msg)
+		}
+
 		switch msg.String() {
 		case "d":
-			history.RemoveByIP(m.table.SelectedRow())
+			cursor := m.table.Cursor()
+			if m.what != SelectHistory || cursor < 0 || cursor >= len(m.lines) || m.lines[cursor].header() {
+				return m, nil
+			}
+
+			entry := m.lines[cursor].entry
+			history.RemoveByIP(m.rows[entry])
 
-			rows := slices.Delete(m.table.Rows(), m.table.Cursor(), m.table.Cursor()+1)
-			m.table.SetRows(rows)
+			m.rows = slices.Delete(m.rows, entry, entry+1)
+			m.entries = slices.Delete(m.entries, entry, entry+1)
+			if entry < len(m.highlights) {
+				m.highlights = slices.Delete(m.highlights, entry, entry+1)
+			}
+			if entry < len(m.boosts) {
+				m.boosts = slices.Delete(m.boosts, entry, entry+1)
+			}
+			if entry < len(m.args) {
+				m.args = slices.Delete(m.args, entry, entry+1)
+			}
+			m.refresh()
+			m.table.SetCursor(cursor)
 
-			m.table, cmd = m.table.Update("") // Overrides default `d` behavior
-			return m, cmd
+			return m, nil
+		case "g":
+			m.grouped = !m.grouped
+			m.refresh()
+			return m, nil
 		case "w":
 			// toggle fullscreen mode
 			newsettings := m.settings
@@ -177,78 +168,249 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					return m, tea.EnterAltScreen
 				} else {
 					// if not fullscreen, set the height to a minimum of 8 rows
-					m.table.SetHeight(int(math.Min(8, float64(len(m.table.Rows())+1))))
+					m.table.SetHeight(m.windowedHeight())
 					return m, tea.ExitAltScreen
 				}
 			}
 
 			// If we can't save the settings, do nothing
 			return m, nil
-		case "q", "ctrl+c", "esc":
+		case "s":
+			m.showDetails = !m.showDetails
+			return m, nil
+		case "tab":
+			m.switchTab(m.active + 1)
+			return m, nil
+		case "shift+tab":
+			m.switchTab(m.active - 1)
+			return m, nil
+		case "f":
+			m.toggleFavorite()
+			return m, nil
+		case "c":
+			return m.startArgs(), nil
+		case "/":
+			return m.startFilter(), nil
+		case "a":
+			if m.what != SelectHistory {
+				return m, nil
+			}
+			return m.startPromote(), nil
+		case "n":
+			if m.what == SelectHistory {
+				return m, nil
+			}
+			return m.startHostForm(), nil
+		case "e":
+			c, ok := m.selected()
+			if !ok || c.SourceFile == "" {
+				return m, nil
+			}
+
+			return m, tea.ExecProcess(editor.Command(c.SourceFile, c.SourceLine), func(err error) tea.Msg {
+				return editorFinishedMsg{err: err}
+			})
+		case "esc":
+			if m.filter.Value() != "" {
+				m.clearFilter()
+				return m, nil
+			}
 			m.exit = true
 			return m, tea.Quit
-		case "enter":
-			m.choice = setConfig(m.table.SelectedRow(), m.what)
+		case "q", "ctrl+c":
+			m.exit = true
 			return m, tea.Quit
+		case "enter":
+			if m.toggleGroup() {
+				return m, nil
+			}
+
+			if args, ok := m.command(); ok {
+				m.choice = args.Argv()
+				return m, tea.Quit
+			}
+			return m, nil
 		}
 	}
 	m.table, cmd = m.table.Update(msg)
 	return m, cmd
 }
 
-func setConfig(row table.Row, what Selecting) config.SSHConfig {
-	return config.SSHConfig{
-		Host: row[1],
-		Port: row[2],
-		User: row[3],
-		Key:  row[4],
+// resize fits the columns to the window, giving the Key column room only for
+// the config hosts.
+func (m *model) resize() {
+	if m.windowWidth == 0 {
+		return
+	}
+
+	widthForTable := max(m.windowWidth-MarginWidth, MinimumTableWidth)
+	// Extra margin for content: the padding around every cell
+	widthForTableContent := widthForTable - ContentExtraMargin*len(m.table.Columns())
+
+	// columns = [Name, Host, Port, User, Key, Jump, Forwards, Tags, Last login]
+	cols := m.table.Columns()
+
+	switch m.what {
+	// SELECT CONFIG
+	case SelectConfig, SelectFavorites:
+		// base widths = 15,20,5,10,10,15,10,10,12 = total 107
+		resizeColumns(cols, []int{15, 20, 5, 10, 10, 15, 10, 10, 12}, widthForTableContent)
+
+	// SELECT HISTORY
+	case SelectHistory:
+		// base widths = 10,20,5,10,0,15,10,10,15 = total 95
+		resizeColumns(cols, []int{10, 20, 5, 10, 0, 15, 10, 10, 15}, widthForTableContent)
+	}
+
+	// Apply the new widths
+	m.table.SetColumns(cols)
+	m.table.SetWidth(widthForTable)
+}
+
+// resizeColumns gives every column its base width and hands the remaining
+// space to the Key column first, then shares it with the Name column. When
+// there is not enough space all columns are scaled down proportionally.
+func resizeColumns(cols []table.Column, baseWidths []int, width int) {
+	const nameColumn, keyColumn = 0, 4
+
+	totalBase := 0
+	for _, w := range baseWidths {
+		totalBase += w
+	}
+
+	if width < totalBase {
+		ratio := float64(width) / float64(totalBase)
+		for i := range cols {
+			cols[i].Width = max(int(math.Round(float64(baseWidths[i])*ratio)), 1)
+		}
+		return
+	}
+
+	leftover := width - totalBase
+	leftoverForKey := 0
+	leftoverForName := 0
+
+	for leftover > 0 {
+		if leftoverForKey < PreferredKeyExtraWidth {
+			leftoverForKey++
+			leftover--
+		} else if leftoverForKey < MaxKeyExtraWidth && leftover > 1 {
+			leftoverForName++
+			leftoverForKey++
+			leftover -= 2
+		} else {
+			leftoverForName++
+			leftover--
+		}
+	}
+
+	for i := range cols {
+		cols[i].Width = baseWidths[i]
+	}
+	cols[nameColumn].Width += leftoverForName
+	cols[keyColumn].Width += leftoverForKey
+}
+
+// windowedHeight is the height of the table outside fullscreen: up to 8 rows
+// plus the header and its border. It does not shrink while filtering, so the
+// picker keeps its place on the screen.
+func (m model) windowedHeight() int {
+	return min(max(len(m.lines), len(m.entries)), 8) + 2
+}
+
+func (m model) selected() (config.SSHConfig, bool) {
+	entry, ok := m.selectedEntry()
+	if !ok {
+		return config.SSHConfig{}, false
+	}
+
+	return m.entries[entry], true
+}
+
+// selectedEntry returns the index of the selected entry, unless a group
+// header is selected.
+func (m model) selectedEntry() (int, bool) {
+	cursor := m.table.Cursor()
+	if cursor < 0 || cursor >= len(m.lines) || m.lines[cursor].header() {
+		return 0, false
+	}
+
+	return m.lines[cursor].entry, true
+}
+
+// reload rebuilds the rows from disk, keeping the cursor on the same entry
+// when it still exists.
+func (m *model) reload() {
+	current, _ := m.selected()
+
+	config.Forget()
+	l, err := m.load()
+	if err != nil {
+		m.err = err
+		return
+	}
+
+	entries := l.entries
+	m.rows, m.entries, m.highlights, m.boosts, m.args = l.rows, l.entries, l.highlights, l.boosts, l.args
+	m.hosts, _ = config.Load()
+	m.recent, _ = history.FetchWithDefaultFile()
+	m.refresh()
+
+	if i := slices.IndexFunc(m.lines, func(l line) bool {
+		return !l.header() && entries[l.entry].Name == current.Name && entries[l.entry].Host == current.Host
+	}); i != -1 {
+		m.table.SetCursor(i)
+	}
+}
+
+// selectName moves the cursor to the entry called name, if it is listed.
+func (m *model) selectName(name string) {
+	for i, l := range m.lines {
+		if !l.header() && m.entries[l.entry].Name == name {
+			m.table.SetCursor(i)
+			return
+		}
 	}
 }
 
 func (m model) View() string {
-	if m.choice.Host != "" || m.exit {
+	if len(m.choice) > 0 || m.exit {
 		return ""
 	}
-	return theme.BaseStyle.Render(m.table.View()) + "\n  " + m.HelpView() + "\n"
-}
 
-func Select(rows []table.Row, what Selecting) config.SSHConfig {
-	var columns []table.Column
-	if what == SelectConfig {
-		columns = append(columns, []table.Column{
-			{Title: "Name"},
-			{Title: "Host"},
-			{Title: "Port"},
-			{Title: "User"},
-			{Title: "Key"},
-		}...)
+	if m.mode == addingHost {
+		return m.form.View() + "\n  " + generateHelpBlock("↑/↓", "field", true) +
+			generateHelpBlock("tab", "complete key", true) + generateHelpBlock("ctrl+s", "save", true) +
+			generateHelpBlock("esc", "cancel", false) + "\n"
+	}
+	view := "  " + m.TabsView() + "\n" + theme.BaseStyle.Render(m.table.View()) + "\n  "
+	if m.err != nil {
+		view += generateHelpBlock("error", m.err.Error(), false) + "\n  "
+	}
+
+	if m.status != "" {
+		view += generateHelpBlock(m.status, "", false) + "\n  "
 	}
 
-	if what == SelectHistory {
-		columns = append(columns, []table.Column{
-			{Title: "Name"},
-			{Title: "Host"},
-			{Title: "Port"},
-			{Title: "User"},
-			{Title: "Key"},
-			{Title: "Last login"},
-		}...)
+	if m.showDetails {
+		view += m.DetailsView() + "\n  "
 	}
 
-	t := table.New(
-		table.WithColumns(columns),
-		table.WithRows(rows),
-		table.WithFocused(true),
-		table.WithHeight(int(math.Min(8, float64(len(rows)+1)))),
-	)
+	if m.mode == filtering || m.filter.Value() != "" {
+		view += m.FilterView() + "\n  "
+	}
 
-	s := table.DefaultStyles()
-	s.Header = s.Header.BorderStyle(lipgloss.NormalBorder()).BorderForeground(lipgloss.Color("240")).BorderBottom(true).Bold(false)
-	s.Selected = s.Selected.Foreground(lipgloss.Color("229")).Background(lipgloss.Color("57")).Bold(false)
+	if m.mode == promotingAlias || m.mode == promotingFile || m.mode == editingArgs {
+		return view + m.input.View() + "\n  " + generateHelpBlock("enter", "confirm", true) + generateHelpBlock("esc", "cancel", false) + "\n"
+	}
 
-	t.SetStyles(s)
+	return view + m.HelpView() + "\n"
+}
 
-	p := tea.NewProgram(model{table: t, what: what})
+// Select runs the picker on tabs, starting on the one showing what, whose
+// listing is l.
+func Select(l listing, tabs []tab, what Selecting) []string {
+	p := tea.NewProgram(newModel(l, tabs, what))
 	m, err := p.Run()
 	if err != nil {
 		fmt.Println("error while running the interactive selector, ", err)
@@ -256,7 +418,7 @@ func Select(rows []table.Row, what Selecting) config.SSHConfig {
 	}
 	// Assert the final tea.Model to our local model and print the choice.
 	if m, ok := m.(model); ok {
-		if m.choice.Host != "" {
+		if len(m.choice) > 0 {
 			return m.choice
 		}
 		if m.exit {
@@ -264,8 +426,46 @@ func Select(rows []table.Row, what Selecting) config.SSHConfig {
 		}
 	}
 
-	return config.SSHConfig{}
+	return nil
 }
+
+func newModel(l listing, tabs []tab, what Selecting) model {
+	columns := []table.Column{
+		{Title: "Name"},
+		{Title: "Host"},
+		{Title: "Port"},
+		{Title: "User"},
+		{Title: "Key"},
+		{Title: "Jump"},
+		{Title: "Forwards"},
+		{Title: "Tags"},
+		{Title: "Last login"},
+	}
+
+	active := slices.IndexFunc(tabs, func(t tab) bool { return t.what == what })
+	hosts, _ := config.Load()
+	recent, _ := history.FetchWithDefaultFile()
+
+	m := model{
+		table:      newHostTable(columns, 0),
+		entries:    l.entries,
+		rows:       l.rows,
+		highlights: l.highlights,
+		boosts:     l.boosts,
+		args:       l.args,
+		what:       what,
+		load:       tabs[active].load,
+		tabs:       tabs,
+		active:     active,
+		hosts:      hosts,
+		recent:     recent,
+		filter:     newInput("Filter: ", ""),
+	}
+	m.refresh()
+
+	return m
+}
+
 func (m model) HelpView() string {
 
 	km := table.DefaultKeyMap()
@@ -274,11 +474,31 @@ func (m model) HelpView() string {
 
 	b.WriteString(generateHelpBlock(km.LineUp.Help().Key, km.LineUp.Help().Desc, true))
 	b.WriteString(generateHelpBlock(km.LineDown.Help().Key, km.LineDown.Help().Desc, true))
+	b.WriteString(generateHelpBlock("tab", "switch list", true))
+
+	if m.mode == filtering {
+		b.WriteString(generateHelpBlock("enter", "connect", true))
+		b.WriteString(generateHelpBlock("esc", "clear filter", false))
+		return b.String()
+	}
+
+	b.WriteString(generateHelpBlock("/", "filter", true))
 
 	if m.what == SelectHistory {
 		b.WriteString(generateHelpBlock("d", "delete", true))
+		b.WriteString(generateHelpBlock("a", "save as host", true))
 	}
 
+	if m.what != SelectHistory {
+		b.WriteString(generateHelpBlock("n", "new host", true))
+	}
+
+	b.WriteString(generateHelpBlock("f", "favorite", true))
+
+	b.WriteString(generateHelpBlock("c", "edit command", true))
+	b.WriteString(generateHelpBlock("g", "group by tag", true))
+	b.WriteString(generateHelpBlock("e", "edit", true))
+	b.WriteString(generateHelpBlock("s", "details", true))
 	b.WriteString(generateHelpBlock("w", "full/windowed", true))
 	b.WriteString(generateHelpBlock("q/esc", "quit", false))
 
@@ -0,0 +1,247 @@
+package interactive
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// hostTable is a scrolling table that works like the one of bubbles, but can
+// highlight single characters of a cell, such as the ones a search matched.
+// The bubbles table truncates cells without regard for escape sequences, so
+// it cannot render styled text.
+type hostTable struct {
+	columns []table.Column
+	rows    []table.Row
+	// highlights holds the rune positions to highlight per row and column.
+	highlights [][][]int
+	cursor     int
+	offset     int
+	height     int
+	width      int
+	keys       table.KeyMap
+	styles     table.Styles
+}
+
+func newHostTable(columns []table.Column, height int) hostTable {
+	s := table.DefaultStyles()
+	s.Header = s.Header.BorderStyle(lipgloss.NormalBorder()).BorderForeground(lipgloss.Color("240")).BorderBottom(true).Bold(false)
+	s.Selected = s.Selected.Foreground(lipgloss.Color("229")).Background(lipgloss.Color("57")).Bold(false)
+
+	return hostTable{columns: columns, height: height, keys: table.DefaultKeyMap(), styles: s}
+}
+
+func (t hostTable) Columns() []table.Column {
+	return t.columns
+}
+
+func (t *hostTable) SetColumns(columns []table.Column) {
+	t.columns = columns
+}
+
+func (t hostTable) Rows() []table.Row {
+	return t.rows
+}
+
+// SetRows replaces the rows and their highlights, which may be nil.
+func (t *hostTable) SetRows(rows []table.Row, highlights [][][]int) {
+	t.rows = rows
+	t.highlights = highlights
+	t.SetCursor(t.cursor)
+}
+
+func (t hostTable) Cursor() int {
+	return t.cursor
+}
+
+func (t *hostTable) SetCursor(cursor int) {
+	t.cursor = max(min(cursor, len(t.rows)-1), 0)
+
+	visible := t.visibleRows()
+	switch {
+	case t.cursor < t.offset:
+		t.offset = t.cursor
+	case t.cursor >= t.offset+visible:
+		t.offset = t.cursor - visible + 1
+	}
+	t.offset = max(min(t.offset, len(t.rows)-visible), 0)
+}
+
+// SetHeight sets the height of the whole table, header included.
+func (t *hostTable) SetHeight(height int) {
+	t.height = height
+	t.SetCursor(t.cursor)
+}
+
+func (t *hostTable) SetWidth(width int) {
+	t.width = width
+}
+
+func (t hostTable) visibleRows() int {
+	return max(t.height-lipgloss.Height(t.headerView()), 1)
+}
+
+func (t hostTable) Update(msg tea.Msg) (hostTable, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return t, nil
+	}
+
+	page := t.visibleRows()
+
+	switch {
+	case key.Matches(keyMsg, t.keys.LineUp):
+		t.SetCursor(t.cursor - 1)
+	case key.Matches(keyMsg, t.keys.LineDown):
+		t.SetCursor(t.cursor + 1)
+	case key.Matches(keyMsg, t.keys.PageUp):
+		t.SetCursor(t.cursor - page)
+	case key.Matches(keyMsg, t.keys.PageDown):
+		t.SetCursor(t.cursor + page)
+	case key.Matches(keyMsg, t.keys.HalfPageUp):
+		t.SetCursor(t.cursor - page/2)
+	case key.Matches(keyMsg, t.keys.HalfPageDown):
+		t.SetCursor(t.cursor + page/2)
+	case key.Matches(keyMsg, t.keys.GotoTop):
+		t.SetCursor(0)
+	case key.Matches(keyMsg, t.keys.GotoBottom):
+		t.SetCursor(len(t.rows) - 1)
+	}
+
+	return t, nil
+}
+
+func (t hostTable) View() string {
+	lines := make([]string, 0, t.visibleRows())
+	for r := t.offset; r < len(t.rows) && len(lines) < t.visibleRows(); r++ {
+		lines = append(lines, t.rowView(r))
+	}
+
+	// Like the viewport of the bubbles table, keep the height and cut what
+	// does not fit the width.
+	for len(lines) < t.visibleRows() {
+		lines = append(lines, "")
+	}
+
+	view := t.headerView() + "\n" + strings.Join(lines, "\n")
+	if t.width > 0 {
+		view = lipgloss.NewStyle().MaxWidth(t.width).Render(view)
+	}
+
+	return view
+}
+
+func (t hostTable) headerView() string {
+	cells := make([]string, 0, len(t.columns))
+	for _, col := range t.columns {
+		if col.Width <= 0 {
+			continue
+		}
+
+		title, _, _ := truncate([]rune(col.Title), col.Width)
+		style := lipgloss.NewStyle().Width(col.Width).MaxWidth(col.Width).Inline(true)
+		cells = append(cells, t.styles.Header.Render(style.Render(string(title))))
+	}
+
+	return lipgloss.JoinHorizontal(lipgloss.Top, cells...)
+}
+
+func (t hostTable) rowView(r int) string {
+	base := lipgloss.NewStyle()
+	mark := base.Foreground(lipgloss.Color("212")).Bold(true)
+	if r == t.cursor {
+		base = t.styles.Selected
+		mark = base.Underline(true).Bold(true)
+	}
+
+	var b strings.Builder
+	for i, col := range t.columns {
+		if col.Width <= 0 {
+			continue
+		}
+
+		var value string
+		if i < len(t.rows[r]) {
+			value = t.rows[r][i]
+		}
+
+		var highlights []int
+		if r < len(t.highlights) && i < len(t.highlights[r]) {
+			highlights = t.highlights[r][i]
+		}
+
+		b.WriteString(base.Render(" "))
+		b.WriteString(cellView(value, col.Width, highlights, base, mark))
+		b.WriteString(base.Render(" "))
+	}
+
+	return b.String()
+}
+
+// cellView renders value truncated and padded to width, with the runes at the
+// highlights positions rendered in the mark style.
+func cellView(value string, width int, highlights []int, base lipgloss.Style, mark lipgloss.Style) string {
+	runes, used, cut := truncate([]rune(value), width)
+
+	var b strings.Builder
+	var run []rune
+	marked := false
+
+	flush := func() {
+		if len(run) == 0 {
+			return
+		}
+		if marked {
+			b.WriteString(mark.Render(string(run)))
+		} else {
+			b.WriteString(base.Render(string(run)))
+		}
+		run = run[:0]
+	}
+
+	for i, r := range runes {
+		// The ellipsis of a truncated value is never highlighted.
+		isMarked := slices.Contains(highlights, i) && (!cut || i < len(runes)-1)
+
+		if isMarked != marked {
+			flush()
+			marked = isMarked
+		}
+		run = append(run, r)
+	}
+	flush()
+
+	if used < width {
+		b.WriteString(base.Render(strings.Repeat(" ", width-used)))
+	}
+
+	return b.String()
+}
+
+// truncate cuts runes to fit in width cells, ending with an ellipsis when it
+// had to cut, and returns the kept runes with the cells they take.
+func truncate(runes []rune, width int) ([]rune, int, bool) {
+	used := 0
+	for _, r := range runes {
+		used += lipgloss.Width(string(r))
+	}
+
+	if used <= width {
+		return runes, used, false
+	}
+
+	used = 0
+	for i, r := range runes {
+		w := lipgloss.Width(string(r))
+		if used+w > width-1 {
+			return append(runes[:i:i], '…'), used + 1, true
+		}
+		used += w
+	}
+
+	return runes, used, false
+}
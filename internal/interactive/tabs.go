@@ -0,0 +1,127 @@
+package interactive
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/byawitz/ggh/internal/settings"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// tab is one of the lists of the picker, switched between with tab.
+type tab struct {
+	title string
+	what  Selecting
+	load  loader
+}
+
+// tabs returns the tabs of the picker, the config ones filtered by query.
+func tabs(query string) []tab {
+	return []tab{
+		{title: "Recent", what: SelectHistory, load: historyRows},
+		{title: "All hosts", what: SelectConfig, load: func() (listing, error) { return configRows(query) }},
+		{title: "Favorites", what: SelectFavorites, load: func() (listing, error) { return favoriteRows(query) }},
+	}
+}
+
+// switchTab shows the tab at index i, which wraps around, with the cursor on
+// its first host. The filter is kept.
+func (m *model) switchTab(i int) {
+	i = (i + len(m.tabs)) % len(m.tabs)
+
+	l, err := m.tabs[i].load()
+	if err != nil {
+		m.err = err
+		return
+	}
+
+	m.active, m.what, m.load = i, m.tabs[i].what, m.tabs[i].load
+	m.rows, m.entries, m.highlights, m.boosts, m.args = l.rows, l.entries, l.highlights, l.boosts, l.args
+	m.err, m.status = nil, ""
+
+	m.resize()
+	m.refresh()
+	m.table.SetCursor(0)
+	m.selectFirst()
+
+	if len(m.entries) == 0 && m.what == SelectFavorites {
+		m.status = "no favorites yet, press f on a host to add it"
+	}
+}
+
+// toggleFavorite adds the selected host to the favorites or removes it.
+func (m *model) toggleFavorite() {
+	c, ok := m.selected()
+	if !ok {
+		return
+	}
+
+	if c.Name == "" {
+		m.status = "only hosts with an alias can be favorites"
+		return
+	}
+
+	s := settings.FetchWithDefaultFile()
+	favorite := s.ToggleFavorite(c.Name)
+	saved, err := settings.Save(s)
+	if err != nil {
+		m.err = err
+		return
+	}
+	m.settings.Favorites = saved.Favorites
+
+	if favorite {
+		m.status = fmt.Sprintf("added %s to favorites", c.Name)
+	} else {
+		m.status = fmt.Sprintf("removed %s from favorites", c.Name)
+	}
+
+	if m.what == SelectFavorites {
+		status := m.status
+		m.reload()
+		m.status = status
+	}
+}
+
+// favoriteRows lists the favorite hosts matching the query value.
+func favoriteRows(value string) (listing, error) {
+	l, err := configRows(value)
+	if err != nil {
+		return listing{}, err
+	}
+
+	s := settings.FetchWithDefaultFile()
+
+	var favorites listing
+	for i, c := range l.entries {
+		if !s.IsFavorite(c.Name) {
+			continue
+		}
+
+		favorites.entries = append(favorites.entries, c)
+		favorites.rows = append(favorites.rows, l.rows[i])
+		favorites.highlights = append(favorites.highlights, l.highlights[i])
+		favorites.boosts = append(favorites.boosts, l.boosts[i])
+	}
+
+	return favorites, nil
+}
+
+func (m model) TabsView() string {
+	active := lipgloss.NewStyle().Foreground(lipgloss.Color("212")).Bold(true).Underline(true)
+	inactive := lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{
+		Light: "#909090",
+		Dark:  "#626262",
+	})
+
+	titles := make([]string, len(m.tabs))
+	for i, t := range m.tabs {
+		if i == m.active {
+			titles[i] = active.Render(t.title)
+		} else {
+			titles[i] = inactive.Render(t.title)
+		}
+	}
+
+	return strings.Join(titles, "   ")
+}
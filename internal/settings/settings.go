@@ -4,10 +4,30 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"slices"
 )
 
 type Settings struct {
-	Fullscreen bool `json:"fullscreen"`
+	Fullscreen      bool     `json:"fullscreen"`
+	ResolveWithSSH  bool     `json:"resolve_with_ssh"`
+	HideSystemHosts bool     `json:"hide_system_hosts"`
+	Favorites       []string `json:"favorites,omitempty"`
+}
+
+func (s Settings) IsFavorite(name string) bool {
+	return slices.Contains(s.Favorites, name)
+}
+
+// ToggleFavorite adds the host alias name to the favorites, or removes it when
+// it already was one, and reports whether it is a favorite now.
+func (s *Settings) ToggleFavorite(name string) bool {
+	if i := slices.Index(s.Favorites, name); i != -1 {
+		s.Favorites = slices.Delete(s.Favorites, i, i+1)
+		return false
+	}
+
+	s.Favorites = append(s.Favorites, name)
+	return true
 }
 
 func FetchWithDefaultFile() Settings {
@@ -28,3 +28,19 @@ func TestMarshal(t *testing.T) {
 	}
 	time.Sleep(100 * time.Millisecond) // Allow time for file operations
 }
+
+func TestToggleFavorite(t *testing.T) {
+	var s Settings
+
+	if !s.ToggleFavorite("db1") || !s.ToggleFavorite("web1") {
+		t.Fatalf("expected db1 and web1 to become favorites, got %v", s.Favorites)
+	}
+
+	if s.ToggleFavorite("db1") {
+		t.Errorf("expected db1 to be removed, got %v", s.Favorites)
+	}
+
+	if s.IsFavorite("db1") || !s.IsFavorite("web1") {
+		t.Errorf("expected only web1 to be a favorite, got %v", s.Favorites)
+	}
+}
@@ -0,0 +1,42 @@
+package ssh
+
+// Args is an ssh command line: its options, the destination and a remote
+// command. Every value stays a single argument of Argv, whatever characters
+// it holds, so no part of it is ever split on spaces.
+type Args struct {
+	// Options holds a flag per option, followed by its value if it takes
+	// one, such as {"-A"} or {"-p", "2222"}.
+	Options     [][]string
+	Destination string
+	Command     []string
+}
+
+// Add appends the flag with its value, if it takes one.
+func (a *Args) Add(flag string, value ...string) {
+	a.Options = append(a.Options, append([]string{flag}, value...))
+}
+
+// Argv returns the arguments to run ssh with.
+func (a Args) Argv() []string {
+	var argv []string
+	for _, option := range a.Options {
+		argv = append(argv, option...)
+	}
+
+	if a.Destination != "" {
+		argv = append(argv, a.Destination)
+	}
+
+	if len(a.Command) > 0 {
+		argv = append(argv, "--")
+		argv = append(argv, a.Command...)
+	}
+
+	return argv
+}
+
+// String renders the command line quoted for a POSIX shell, ready to be
+// copied into a terminal.
+func (a Args) String() string {
+	return "ssh " + Quote(a.Argv())
+}
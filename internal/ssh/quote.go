@@ -0,0 +1,96 @@
+package ssh
+
+import (
+	"errors"
+	"strings"
+)
+
+// Quote renders args as a POSIX shell would need them typed, quoting only the
+// arguments that need it.
+func Quote(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = quote(arg)
+	}
+
+	return strings.Join(quoted, " ")
+}
+
+func quote(arg string) string {
+	if arg == "" {
+		return "''"
+	}
+
+	// A leading ~ would be expanded by the local shell, and so may one after
+	// an =, so both are quoted to reach ssh as they are.
+	if !strings.HasPrefix(arg, "~") && !strings.ContainsFunc(arg, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("@%+:,./_-~", r))
+	}) {
+		return arg
+	}
+
+	return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+}
+
+// Split splits a command line typed as for a POSIX shell into its arguments,
+// honouring single and double quotes and backslashes. It is the reverse of
+// Quote.
+func Split(line string) ([]string, error) {
+	var args []string
+	var current strings.Builder
+	inArg := false
+
+	runes := []rune(line)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+
+		switch {
+		case r == ' ' || r == '\t' || r == '\n':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		case r == '\'':
+			inArg = true
+			end := i + 1
+			for end < len(runes) && runes[end] != '\'' {
+				end++
+			}
+			if end == len(runes) {
+				return nil, errors.New("unterminated single quote")
+			}
+			current.WriteString(string(runes[i+1 : end]))
+			i = end
+		case r == '"':
+			inArg = true
+			i++
+			for ; i < len(runes) && runes[i] != '"'; i++ {
+				// Inside double quotes a backslash only escapes the characters
+				// that are special there.
+				if runes[i] == '\\' && i+1 < len(runes) && strings.ContainsRune("\"\\$`\n", runes[i+1]) {
+					i++
+				}
+				current.WriteRune(runes[i])
+			}
+			if i == len(runes) {
+				return nil, errors.New("unterminated double quote")
+			}
+		case r == '\\':
+			inArg = true
+			if i+1 < len(runes) {
+				i++
+				current.WriteRune(runes[i])
+			}
+		default:
+			inArg = true
+			current.WriteRune(r)
+		}
+	}
+
+	if inArg {
+		args = append(args, current.String())
+	}
+
+	return args, nil
+}
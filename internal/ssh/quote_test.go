@@ -0,0 +1,56 @@
+package ssh
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestQuote(t *testing.T) {
+	tests := []struct {
+		args   []string
+		quoted string
+	}{
+		{[]string{"root@web", "-p", "22"}, "root@web -p 22"},
+		{[]string{"-i", "/Users/Jane Doe/.ssh/id_ed25519"}, "-i '/Users/Jane Doe/.ssh/id_ed25519'"},
+		{[]string{"web", "--", "echo", "it's $HOME"}, `web -- echo 'it'\''s $HOME'`},
+		{[]string{"-o", ""}, "-o ''"},
+		{[]string{"-o", "User=deploy", "web", "--", "ls", "~", "a~b"}, "-o 'User=deploy' web -- ls '~' a~b"},
+	}
+
+	for _, tt := range tests {
+		quoted := Quote(tt.args)
+		if quoted != tt.quoted {
+			t.Errorf("Quote(%q) = %s, want %s", tt.args, quoted, tt.quoted)
+		}
+
+		args, err := Split(quoted)
+		if err != nil || !slices.Equal(args, tt.args) {
+			t.Errorf("Split(%s) = %q, %v, want %q", quoted, args, err, tt.args)
+		}
+	}
+}
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		line string
+		args []string
+	}{
+		{"  web   -A ", []string{"web", "-A"}},
+		{`-o "ProxyCommand=ssh -W %h:%p \"bastion\""`, []string{"-o", `ProxyCommand=ssh -W %h:%p "bastion"`}},
+		{`-i My\ Key web`, []string{"-i", "My Key", "web"}},
+		{`a""b ''`, []string{"ab", ""}},
+	}
+
+	for _, tt := range tests {
+		args, err := Split(tt.line)
+		if err != nil || !slices.Equal(args, tt.args) {
+			t.Errorf("Split(%s) = %q, %v, want %q", tt.line, args, err, tt.args)
+		}
+	}
+
+	for _, line := range []string{`web 'ls`, `web "ls`} {
+		if _, err := Split(line); err == nil {
+			t.Errorf("Split(%s) succeeded", line)
+		}
+	}
+}
@@ -1,38 +1,94 @@
 package ssh
 
 import (
+	"errors"
 	"fmt"
 	"github.com/byawitz/ggh/internal/config"
 	"os"
 	"os/exec"
-	"slices"
 	"strings"
 )
 
-func GenerateCommandArgs(c config.SSHConfig) []string {
-	key, port := "", ""
-	user := "root"
+// GenerateCommandArgs returns the ssh arguments to connect to c. A host of
+// the ssh config is connected to by its alias, so that ssh applies its whole
+// Host block; only connections without one are spelled out.
+func GenerateCommandArgs(c config.SSHConfig) Args {
+	if c.Name != "" && c.SourceFile != "" {
+		return Args{Destination: c.Name}
+	}
+
+	args := Args{Destination: c.Host}
 
 	if c.User != "" {
-		user = c.User
+		args.Destination = c.User + "@" + c.Host
 	}
 
+	if c.Port != "" {
+		args.Add("-p", c.Port)
+	}
+
+	// ssh tries the identity files in order, the key first.
 	if c.Key != "" {
-		key = "-i " + c.Key
+		args.Add("-i", c.Key)
 	}
 
-	if c.Port != "" {
-		port = "-p " + c.Port
+	for _, identityFile := range c.IdentityFiles {
+		if identityFile != c.Key {
+			args.Add("-i", identityFile)
+		}
+	}
+
+	if c.ProxyJump != "" {
+		args.Add("-J", c.ProxyJump)
+	}
+
+	if c.ProxyCommand != "" {
+		args.Add("-o", "ProxyCommand="+c.ProxyCommand)
+	}
+
+	if c.ForwardAgent != "" {
+		args.Add("-o", "ForwardAgent="+c.ForwardAgent)
+	}
+
+	for _, f := range c.LocalForward {
+		args.Add("-L", forwardSpec(f))
+	}
+
+	for _, f := range c.RemoteForward {
+		args.Add("-R", forwardSpec(f))
 	}
-	return strings.Split(fmt.Sprintf("%s@%s %s %s", user, c.Host, key, port), " ")
+
+	for _, f := range c.DynamicForward {
+		args.Add("-D", f)
+	}
+
+	return args
 }
 
-func Run(args []string) {
-	args = slices.DeleteFunc(args, func(s string) bool { return s == "" })
+// forwardSpec converts the "listen target" form of ssh_config into the
+// "listen:target" form of the -L and -R flags.
+func forwardSpec(forward string) string {
+	return strings.Join(strings.Fields(forward), ":")
+}
 
-	cmd := exec.Command("ssh", args...)
+// Run runs ssh with args and returns its exit code.
+func Run(args []string) int {
+	cmd := exec.Command("ssh", config.WithConfigFlag(args)...)
 	cmd.Stdin = os.Stdin
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
-	_ = cmd.Run()
+
+	err := cmd.Run()
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+
+	if err != nil {
+		fmt.Println("error running ssh,", err)
+		return 1
+	}
+
+	return 0
 }
@@ -0,0 +1,43 @@
+package ssh
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/byawitz/ggh/internal/config"
+)
+
+func TestGenerateCommandArgs(t *testing.T) {
+	alias := config.SSHConfig{Name: "db1", Host: "10.0.0.1", User: "root", Port: "2222", ProxyJump: "bastion", SourceFile: "/home/me/.ssh/config"}
+	if args := GenerateCommandArgs(alias).Argv(); !slices.Equal(args, []string{"db1"}) {
+		t.Errorf("GenerateCommandArgs(%q) = %q, want [db1]", alias.Name, args)
+	}
+
+	// A history entry whose Host block is gone is spelled out.
+	alias.SourceFile = ""
+	alias.Key = "/Users/Jane Doe/.ssh/id_ed25519"
+	alias.IdentityFiles = []string{alias.Key, "~/.ssh/id_rsa"}
+
+	args := GenerateCommandArgs(alias)
+	want := []string{"-p", "2222", "-i", "/Users/Jane Doe/.ssh/id_ed25519", "-i", "~/.ssh/id_rsa", "-J", "bastion", "root@10.0.0.1"}
+	if !slices.Equal(args.Argv(), want) {
+		t.Errorf("GenerateCommandArgs() = %q, want %q", args.Argv(), want)
+	}
+
+	if quoted := "ssh -p 2222 -i '/Users/Jane Doe/.ssh/id_ed25519' -i '~/.ssh/id_rsa' -J bastion root@10.0.0.1"; args.String() != quoted {
+		t.Errorf("GenerateCommandArgs().String() = %s, want %s", args.String(), quoted)
+	}
+}
+
+func TestArgv(t *testing.T) {
+	var args Args
+	args.Add("-A")
+	args.Add("-o", "")
+	args.Destination = "web"
+	args.Command = []string{"echo", "-n", "a b"}
+
+	want := []string{"-A", "-o", "", "web", "--", "echo", "-n", "a b"}
+	if !slices.Equal(args.Argv(), want) {
+		t.Errorf("Argv() = %q, want %q", args.Argv(), want)
+	}
+}
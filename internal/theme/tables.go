@@ -19,6 +19,13 @@ func PrintTable(rows []table.Row, p Print) string {
 		{Title: "Port", Width: 10},
 		{Title: "User", Width: 10},
 		{Title: "Key", Width: 10},
+		{Title: "Jump", Width: 12},
+		{Title: "Forwards", Width: 12},
+		{Title: "Tags", Width: 15},
+	}
+
+	if p == PrintConfig {
+		columns = append(columns, table.Column{Title: "Source", Width: 25})
 	}
 
 	if p == PrintHistory {